@@ -0,0 +1,177 @@
+package service
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// OverflowPolicy controls what an AsyncWriter does when its queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes Write wait until the queue has room.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest queued write to make room for the new one.
+	OverflowDropOldest
+)
+
+// defaultAsyncQueueSize is the queue size used when NewAsyncWriter is given a non-positive size.
+const defaultAsyncQueueSize = 1024
+
+// AsyncWriter is an io.WriteCloser that queues writes and forwards them to an
+// underlying writer on a dedicated goroutine, so that slow sinks (files, network)
+// do not add latency to the code that logs.
+//
+// Each call to Write is queued as a single unit, which keeps records written by a
+// slog.Handler intact. Close must be called at shutdown to drain the queue.
+type AsyncWriter struct {
+	w      io.Writer
+	size   int
+	policy OverflowPolicy
+
+	mu          sync.Mutex
+	cond        *sync.Cond
+	queue       [][]byte
+	closed      bool
+	dropped     uint64
+	writeErrors uint64
+
+	// queued counts the writes accepted so far, and settled those that have since
+	// been passed to the underlying writer or dropped.
+	queued  uint64
+	settled uint64
+
+	done chan struct{}
+}
+
+// NewAsyncWriter returns an AsyncWriter forwarding to w with a queue holding up to
+// size pending writes, applying policy once the queue is full.
+//
+// If size is not positive, a default of 1024 is used.
+func NewAsyncWriter(w io.Writer, size int, policy OverflowPolicy) *AsyncWriter {
+	if size <= 0 {
+		size = defaultAsyncQueueSize
+	}
+
+	a := &AsyncWriter{
+		w:      w,
+		size:   size,
+		policy: policy,
+		queue:  make([][]byte, 0, size),
+		done:   make(chan struct{}),
+	}
+	a.cond = sync.NewCond(&a.mu)
+
+	go a.run()
+
+	return a
+}
+
+// Write queues a copy of p and returns without waiting for it to reach the underlying writer.
+//
+// Errors from the underlying writer are not reported here; they are counted and
+// available through WriteErrors. Write returns os.ErrClosed after Close has been called.
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	buf := append([]byte(nil), p...)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for !a.closed && len(a.queue) >= a.size {
+		if a.policy == OverflowDropOldest {
+			a.queue[0] = nil
+			a.queue = a.queue[1:]
+			a.dropped++
+			a.settled++
+			continue
+		}
+
+		a.cond.Wait()
+	}
+
+	if a.closed {
+		return 0, os.ErrClosed
+	}
+
+	a.queue = append(a.queue, buf)
+	a.queued++
+	a.cond.Broadcast()
+
+	return len(p), nil
+}
+
+// Flush blocks until every write queued before the call has been passed to the
+// underlying writer or dropped. Writes queued while Flush waits are not waited for.
+func (a *AsyncWriter) Flush() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	target := a.queued
+	for a.settled < target {
+		a.cond.Wait()
+	}
+}
+
+// Close stops accepting writes and blocks until the queue has been drained.
+//
+// The underlying writer is not closed; it remains owned by the caller.
+func (a *AsyncWriter) Close() error {
+	a.mu.Lock()
+	a.closed = true
+	a.cond.Broadcast()
+	a.mu.Unlock()
+
+	<-a.done
+
+	return nil
+}
+
+// Dropped returns the number of writes discarded by the OverflowDropOldest policy.
+func (a *AsyncWriter) Dropped() uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.dropped
+}
+
+// WriteErrors returns the number of writes the underlying writer failed.
+func (a *AsyncWriter) WriteErrors() uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.writeErrors
+}
+
+// run forwards queued writes to the underlying writer until the AsyncWriter is closed and drained.
+func (a *AsyncWriter) run() {
+	defer close(a.done)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for {
+		for len(a.queue) == 0 && !a.closed {
+			a.cond.Wait()
+		}
+
+		if len(a.queue) == 0 {
+			return
+		}
+
+		buf := a.queue[0]
+		a.queue[0] = nil
+		a.queue = a.queue[1:]
+		a.cond.Broadcast()
+
+		a.mu.Unlock()
+		_, err := a.w.Write(buf)
+		a.mu.Lock()
+
+		a.settled++
+		if err != nil {
+			a.writeErrors++
+		}
+		a.cond.Broadcast()
+	}
+}
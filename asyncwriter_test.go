@@ -0,0 +1,177 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gatedWriter is an io.Writer whose writes block until its gate is opened.
+type gatedWriter struct {
+	entered chan struct{}
+	gate    chan struct{}
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{entered: make(chan struct{}, 1), gate: make(chan struct{})}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	select {
+	case w.entered <- struct{}{}:
+	default:
+	}
+	<-w.gate
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.Write(p)
+}
+
+func (w *gatedWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.String()
+}
+
+func TestAsyncWriterDropOldest(t *testing.T) {
+	w := newGatedWriter()
+	a := NewAsyncWriter(w, 2, OverflowDropOldest)
+
+	// The first write is taken off the queue and blocks in the underlying writer.
+	_, _ = a.Write([]byte("0 "))
+	<-w.entered
+
+	for i := 1; i <= 5; i++ {
+		if _, err := a.Write([]byte(strconv.Itoa(i) + " ")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if dropped := a.Dropped(); dropped != 3 {
+		t.Errorf("got %d dropped writes, want 3", dropped)
+	}
+
+	close(w.gate)
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := w.String(), "0 4 5 "; got != want {
+		t.Errorf("got output %q, want %q", got, want)
+	}
+}
+
+func TestAsyncWriterBlock(t *testing.T) {
+	w := newGatedWriter()
+	a := NewAsyncWriter(w, 1, OverflowBlock)
+
+	_, _ = a.Write([]byte("0 "))
+	<-w.entered
+	_, _ = a.Write([]byte("1 "))
+
+	written := make(chan struct{})
+	go func() {
+		_, _ = a.Write([]byte("2 "))
+		close(written)
+	}()
+
+	select {
+	case <-written:
+		t.Fatal("Write returned while the queue was full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(w.gate)
+	<-written
+
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := w.String(), "0 1 2 "; got != want {
+		t.Errorf("got output %q, want %q", got, want)
+	}
+	if dropped := a.Dropped(); dropped != 0 {
+		t.Errorf("got %d dropped writes, want 0", dropped)
+	}
+}
+
+func TestAsyncWriterCloseDrains(t *testing.T) {
+	w := newGatedWriter()
+	close(w.gate)
+	a := NewAsyncWriter(w, 1000, OverflowBlock)
+
+	var want strings.Builder
+	for i := range 1000 {
+		line := strconv.Itoa(i) + "\n"
+		want.WriteString(line)
+		if _, err := a.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if w.String() != want.String() {
+		t.Error("output after Close does not contain every write in order")
+	}
+
+	if _, err := a.Write([]byte("late\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Write after Close returned %v, want %v", err, os.ErrClosed)
+	}
+}
+
+func TestAsyncWriterFlushUnderSteadyWrites(t *testing.T) {
+	w := newGatedWriter()
+	close(w.gate)
+	a := NewAsyncWriter(w, 16, OverflowBlock)
+	defer a.Close()
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				_, _ = a.Write([]byte("background\n"))
+			}
+		}
+	})
+	defer func() {
+		close(stop)
+		wg.Wait()
+	}()
+
+	_, _ = a.Write([]byte("marker\n"))
+
+	flushed := make(chan struct{})
+	go func() {
+		a.Flush()
+		close(flushed)
+	}()
+
+	select {
+	case <-flushed:
+	case <-time.After(time.Second):
+		t.Fatal("Flush did not return under steady writes")
+	}
+
+	if !strings.Contains(w.String(), "marker\n") {
+		t.Error("write queued before Flush was not written when Flush returned")
+	}
+}
@@ -0,0 +1,385 @@
+// Package backfill runs controlled replays over a source of items, with rate limiting,
+// bounded concurrency, progress checkpoints and pause/resume.
+package backfill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// defaultCheckpointEvery is the number of items between checkpoints when none is configured.
+const defaultCheckpointEvery = 100
+
+// Cursor iterates over the items of a backfill source.
+type Cursor[T any] interface {
+	// Next returns the next item and the position of the cursor after it.
+	// It returns io.EOF once the source is exhausted.
+	Next(ctx context.Context) (item T, position string, err error)
+}
+
+// Func processes a single item.
+type Func[T any] func(ctx context.Context, item T) error
+
+// CheckpointFunc persists a position from which a later run can resume.
+type CheckpointFunc func(ctx context.Context, position string) error
+
+// Stats is a point-in-time snapshot of a Runner's progress.
+type Stats struct {
+	// Processed is the number of items processed successfully.
+	Processed uint64
+	// Failed is the number of items whose processing returned an error.
+	Failed uint64
+	// Position is the position up to which every item has been processed.
+	Position string
+	// Paused reports whether the runner is paused.
+	Paused bool
+}
+
+// config holds the settings applied by Options.
+type config struct {
+	rate            float64
+	concurrency     int
+	checkpointEvery int
+	checkpoint      CheckpointFunc
+}
+
+// Option configures a Runner.
+type Option func(*config)
+
+// WithRate limits processing to perSecond items per second. Zero means unlimited.
+func WithRate(perSecond float64) Option {
+	return func(c *config) {
+		c.rate = perSecond
+	}
+}
+
+// WithConcurrency sets the number of items processed in parallel. The default is 1.
+func WithConcurrency(n int) Option {
+	return func(c *config) {
+		c.concurrency = n
+	}
+}
+
+// WithCheckpoint makes the runner call fn with its position each time another
+// every items have been processed, and once more when it stops.
+//
+// The position passed to fn is that of the last item before which every item has
+// been processed, so resuming from it never skips work, even with concurrency.
+func WithCheckpoint(every int, fn CheckpointFunc) Option {
+	return func(c *config) {
+		c.checkpointEvery = every
+		c.checkpoint = fn
+	}
+}
+
+// Runner processes the items of a Cursor.
+type Runner[T any] struct {
+	cursor Cursor[T]
+	fn     Func[T]
+	cfg    config
+
+	mu      sync.Mutex
+	stats   Stats
+	resumed chan struct{}
+	next    uint64
+	done    map[uint64]string
+	// checkpointed is the value of next at the last successful checkpoint.
+	checkpointed uint64
+
+	// checkpointMu serialises calls to the CheckpointFunc, which run without mu held.
+	checkpointMu sync.Mutex
+}
+
+// New returns a Runner applying fn to every item of cursor.
+func New[T any](cursor Cursor[T], fn Func[T], opts ...Option) *Runner[T] {
+	cfg := config{
+		concurrency:     1,
+		checkpointEvery: defaultCheckpointEvery,
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.concurrency < 1 {
+		cfg.concurrency = 1
+	}
+	if cfg.checkpointEvery < 1 {
+		cfg.checkpointEvery = defaultCheckpointEvery
+	}
+
+	resumed := make(chan struct{})
+	close(resumed)
+
+	return &Runner[T]{
+		cursor:  cursor,
+		fn:      fn,
+		cfg:     cfg,
+		resumed: resumed,
+		done:    make(map[uint64]string),
+	}
+}
+
+// Stats returns a snapshot of the runner's progress.
+func (r *Runner[T]) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.stats
+}
+
+// Pause stops the runner from reading further items. Items already being processed complete.
+func (r *Runner[T]) Pause() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stats.Paused {
+		return
+	}
+
+	r.stats.Paused = true
+	r.resumed = make(chan struct{})
+}
+
+// Resume continues a paused runner.
+func (r *Runner[T]) Resume() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.stats.Paused {
+		return
+	}
+
+	r.stats.Paused = false
+	close(r.resumed)
+}
+
+// Run processes items until the cursor is exhausted, an item fails, the cursor
+// returns an error or ctx is cancelled.
+//
+// When an item fails, no further items are read, items already queued are skipped,
+// and Run returns the item's error once in-flight items have completed. A final
+// checkpoint is written before Run returns.
+//
+// Run may be called again on the same Runner, for example to resume after an error
+// with the cursor repositioned at the last checkpoint. Progress continues from
+// Stats().Position; items a previous run completed beyond a failed item are not
+// remembered and count as unprocessed.
+func (r *Runner[T]) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	r.mu.Lock()
+	clear(r.done)
+	start := r.next
+	r.mu.Unlock()
+
+	type work struct {
+		seq      uint64
+		item     T
+		position string
+	}
+
+	queue := make(chan work)
+
+	var wg sync.WaitGroup
+	for range r.cfg.concurrency {
+		wg.Go(func() {
+			for w := range queue {
+				// Once the run is stopping, skip queued items rather than
+				// running them with a cancelled context.
+				if ctx.Err() != nil {
+					continue
+				}
+
+				if err := r.fn(ctx, w.item); err != nil {
+					r.mu.Lock()
+					r.stats.Failed++
+					r.mu.Unlock()
+
+					cancel(fmt.Errorf("process item at %q: %w", w.position, err))
+					continue
+				}
+
+				if err := r.complete(ctx, w.seq, w.position); err != nil {
+					cancel(err)
+				}
+			}
+		})
+	}
+
+	err := func() error {
+		defer close(queue)
+
+		var (
+			seq  = start
+			pace = newPacer(r.cfg.rate)
+		)
+		for {
+			if err := r.waitResumed(ctx); err != nil {
+				return err
+			}
+			if err := pace.wait(ctx); err != nil {
+				return err
+			}
+
+			item, position, err := r.cursor.Next(ctx)
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("read next item: %w", err)
+			}
+
+			select {
+			case queue <- work{seq: seq, item: item, position: position}:
+				seq++
+			case <-ctx.Done():
+				return context.Cause(ctx)
+			}
+		}
+	}()
+
+	wg.Wait()
+
+	if cause := context.Cause(ctx); err == nil && cause != nil {
+		err = cause
+	}
+
+	return errors.Join(err, r.flush(context.WithoutCancel(ctx)))
+}
+
+// complete records that the item with sequence number seq has been processed and
+// advances the position over every contiguously processed item, checkpointing if due.
+func (r *Runner[T]) complete(ctx context.Context, seq uint64, position string) error {
+	r.mu.Lock()
+
+	r.stats.Processed++
+	r.done[seq] = position
+
+	for {
+		done, ok := r.done[r.next]
+		if !ok {
+			break
+		}
+
+		delete(r.done, r.next)
+		r.next++
+		r.stats.Position = done
+	}
+
+	next, position := r.next, r.stats.Position
+	due := next-r.checkpointed >= uint64(r.cfg.checkpointEvery)
+
+	r.mu.Unlock()
+
+	if !due {
+		return nil
+	}
+
+	return r.checkpoint(ctx, next, position, uint64(r.cfg.checkpointEvery))
+}
+
+// flush writes a checkpoint if any progress has been made since the last one.
+func (r *Runner[T]) flush(ctx context.Context) error {
+	r.mu.Lock()
+	next, position := r.next, r.stats.Position
+	r.mu.Unlock()
+
+	return r.checkpoint(ctx, next, position, 1)
+}
+
+// checkpoint persists position, the position before the item with sequence number
+// next, if at least every items have been processed since the last checkpoint.
+//
+// The CheckpointFunc is called without r.mu held, so it may use the Runner. Calls are
+// serialised, and a snapshot older than one already persisted is discarded, so
+// checkpointed positions never move backwards.
+func (r *Runner[T]) checkpoint(ctx context.Context, next uint64, position string, every uint64) error {
+	if r.cfg.checkpoint == nil {
+		return nil
+	}
+
+	r.checkpointMu.Lock()
+	defer r.checkpointMu.Unlock()
+
+	r.mu.Lock()
+	checkpointed := r.checkpointed
+	r.mu.Unlock()
+
+	if next < checkpointed+every {
+		return nil
+	}
+
+	if err := r.cfg.checkpoint(ctx, position); err != nil {
+		return fmt.Errorf("checkpoint at %q: %w", position, err)
+	}
+
+	r.mu.Lock()
+	r.checkpointed = next
+	r.mu.Unlock()
+
+	return nil
+}
+
+// waitResumed blocks while the runner is paused.
+func (r *Runner[T]) waitResumed(ctx context.Context) error {
+	r.mu.Lock()
+	resumed := r.resumed
+	r.mu.Unlock()
+
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// pacer spaces out events to a fixed rate.
+type pacer struct {
+	interval time.Duration
+	next     time.Time
+}
+
+// newPacer returns a pacer allowing perSecond events per second, or an unlimited one if perSecond is not positive.
+func newPacer(perSecond float64) *pacer {
+	if perSecond <= 0 {
+		return &pacer{}
+	}
+
+	return &pacer{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// wait blocks until the next event is allowed.
+func (p *pacer) wait(ctx context.Context) error {
+	if p.interval == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	if p.next.Before(now) {
+		p.next = now
+	}
+
+	delay := p.next.Sub(now)
+	p.next = p.next.Add(p.interval)
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
@@ -0,0 +1,180 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// counter is a Cursor over the integers 1 to n, using each integer as its position.
+type counter struct {
+	last, n int
+}
+
+func (c *counter) Next(context.Context) (int, string, error) {
+	if c.last >= c.n {
+		return 0, "", io.EOF
+	}
+
+	c.last++
+	return c.last, strconv.Itoa(c.last), nil
+}
+
+func TestRunnerCheckpointsContiguousPositions(t *testing.T) {
+	const items = 500
+
+	var (
+		mu        sync.Mutex
+		processed = make(map[int]bool)
+		positions []int
+		runner    *Runner[int]
+	)
+
+	process := func(_ context.Context, item int) error {
+		time.Sleep(time.Duration(rand.IntN(100)) * time.Microsecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		processed[item] = true
+
+		return nil
+	}
+
+	checkpoint := func(_ context.Context, position string) error {
+		// The runner must not hold its lock while checkpointing.
+		if got := runner.Stats().Position; got == "" {
+			t.Error("Stats reported no position during a checkpoint")
+		}
+
+		p, err := strconv.Atoi(position)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		for i := 1; i <= p; i++ {
+			if !processed[i] {
+				t.Errorf("checkpoint at %d before item %d was processed", p, i)
+			}
+		}
+		if n := len(positions); n > 0 && positions[n-1] >= p {
+			t.Errorf("checkpoint at %d after checkpoint at %d", p, positions[n-1])
+		}
+		positions = append(positions, p)
+
+		return nil
+	}
+
+	runner = New(&counter{n: items}, process, WithConcurrency(8), WithCheckpoint(10, checkpoint))
+	if err := runner.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := runner.Stats()
+	if stats.Processed != items || stats.Failed != 0 {
+		t.Errorf("got %d processed and %d failed, want %d and 0", stats.Processed, stats.Failed, items)
+	}
+	if stats.Position != strconv.Itoa(items) {
+		t.Errorf("got position %q, want %q", stats.Position, strconv.Itoa(items))
+	}
+	if last := positions[len(positions)-1]; last != items {
+		t.Errorf("last checkpoint at %d, want %d", last, items)
+	}
+}
+
+func TestRunnerRerunAfterFailure(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	var (
+		failing    = true
+		checkpoint string
+	)
+
+	process := func(_ context.Context, item int) error {
+		if item == 4 && failing {
+			return errFailed
+		}
+		return nil
+	}
+
+	cursor := &counter{n: 6}
+	runner := New(cursor, process, WithCheckpoint(1, func(_ context.Context, position string) error {
+		checkpoint = position
+		return nil
+	}))
+
+	if err := runner.Run(context.Background()); !errors.Is(err, errFailed) {
+		t.Fatalf("got error %v, want %v", err, errFailed)
+	}
+
+	stats := runner.Stats()
+	if stats.Processed != 3 || stats.Failed != 1 || stats.Position != "3" || checkpoint != "3" {
+		t.Fatalf("after failure got %+v and checkpoint %q, want 3 processed, 1 failed at position 3", stats, checkpoint)
+	}
+
+	// Resume from the checkpoint, as a caller would.
+	failing = false
+	cursor.last, _ = strconv.Atoi(checkpoint)
+
+	if err := runner.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	stats = runner.Stats()
+	if stats.Processed != 6 || stats.Position != "6" || checkpoint != "6" {
+		t.Errorf("after rerun got %+v and checkpoint %q, want 6 processed at position 6", stats, checkpoint)
+	}
+}
+
+func TestRunnerPauseResume(t *testing.T) {
+	const items = 10
+
+	var runner *Runner[int]
+	runner = New(&counter{n: items}, func(context.Context, int) error { return nil },
+		WithCheckpoint(1, func(_ context.Context, position string) error {
+			if position == "2" {
+				runner.Pause()
+			}
+			return nil
+		}),
+	)
+
+	errc := make(chan error, 1)
+	go func() { errc <- runner.Run(context.Background()) }()
+
+	deadline := time.Now().Add(time.Second)
+	for !runner.Stats().Paused {
+		if time.Now().After(deadline) {
+			t.Fatal("runner did not pause")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// At most the item read before pausing may still complete.
+	time.Sleep(20 * time.Millisecond)
+	if processed := runner.Stats().Processed; processed > 3 {
+		t.Errorf("processed %d items while paused, want at most 3", processed)
+	}
+
+	runner.Resume()
+
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("runner did not finish after resuming")
+	}
+
+	if processed := runner.Stats().Processed; processed != items {
+		t.Errorf("processed %d items, want %d", processed, items)
+	}
+}
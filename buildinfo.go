@@ -0,0 +1,113 @@
+package service
+
+import (
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// BuildInfo describes the build of the running binary, as recorded by the Go toolchain.
+type BuildInfo struct {
+	// ModuleVersion is the version of the main module, or "(devel)" for local builds.
+	ModuleVersion string
+	// Revision is the VCS revision the binary was built from.
+	Revision string
+	// Time is the commit time of Revision.
+	Time time.Time
+	// Modified reports whether the working tree had uncommitted changes at build time.
+	Modified bool
+}
+
+// readBuildInfo reads the build information once and caches it for the life of the process.
+var readBuildInfo = sync.OnceValue(func() BuildInfo {
+	var info BuildInfo
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	info.ModuleVersion = bi.Main.Version
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Revision = setting.Value
+		case "vcs.time":
+			info.Time, _ = time.Parse(time.RFC3339, setting.Value)
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+
+	return info
+})
+
+// ReadBuildInfo returns the build information of the running binary.
+//
+// Fields are left empty if the binary was built without module or VCS information.
+func ReadBuildInfo() BuildInfo {
+	return readBuildInfo()
+}
+
+// ResolveVersion returns version if it is not empty, and otherwise derives a version from
+// the build information: the main module version for released builds, or the short VCS
+// revision, suffixed with "-dirty" for modified trees, for local builds.
+//
+// It returns "unknown" if no version can be derived.
+func ResolveVersion(version string) string {
+	if version != "" {
+		return version
+	}
+
+	info := ReadBuildInfo()
+	if info.ModuleVersion != "" && info.ModuleVersion != "(devel)" {
+		return info.ModuleVersion
+	}
+
+	if info.Revision == "" {
+		return "unknown"
+	}
+
+	revision := info.Revision
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if info.Modified {
+		revision += "-dirty"
+	}
+
+	return revision
+}
+
+// LogAttrs returns the build information as logger attributes, suitable for WithChildLogger.
+//
+// It returns nil if the binary carries no VCS information.
+func (b BuildInfo) LogAttrs() []any {
+	if b.Revision == "" {
+		return nil
+	}
+
+	return []any{
+		"vcs_revision", b.Revision,
+		"vcs_time", b.Time,
+		"vcs_modified", b.Modified,
+	}
+}
+
+// Attributes returns the build information as OpenTelemetry attributes,
+// suitable for the service resource.
+//
+// It returns nil if the binary carries no VCS information.
+func (b BuildInfo) Attributes() []attribute.KeyValue {
+	if b.Revision == "" {
+		return nil
+	}
+
+	return []attribute.KeyValue{
+		attribute.String("vcs.revision", b.Revision),
+		attribute.String("vcs.time", b.Time.Format(time.RFC3339)),
+		attribute.Bool("vcs.modified", b.Modified),
+	}
+}
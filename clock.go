@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// processStart is the time the package was initialised. It carries a monotonic
+// clock reading, which Uptime relies on.
+var processStart = time.Now()
+
+// Uptime returns how long the process has been running.
+//
+// It is measured on the monotonic clock and is therefore unaffected by changes
+// to the wall clock.
+func Uptime() time.Duration {
+	return time.Since(processStart)
+}
+
+// ClockJump describes a discontinuity between the wall clock and the monotonic clock,
+// as caused by clock steps or by the host being suspended and resumed.
+type ClockJump struct {
+	// At is the wall-clock time at which the jump was detected.
+	At time.Time
+	// Offset is how far the wall clock moved relative to the monotonic clock.
+	// It is positive when the wall clock jumped forward.
+	Offset time.Duration
+}
+
+// WatchClock compares the progress of the wall clock with that of the monotonic clock
+// every interval and reports a ClockJump whenever they diverge by more than threshold,
+// until ctx is cancelled.
+//
+// Every jump is logged as a warning to the logger in ctx and, if fn is not nil,
+// passed to fn.
+func WatchClock(ctx context.Context, interval, threshold time.Duration, fn func(ClockJump)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	prev := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		// Round(0) strips the monotonic reading, so the second subtraction uses wall time only.
+		offset := now.Round(0).Sub(prev.Round(0)) - now.Sub(prev)
+		prev = now
+
+		if offset.Abs() <= threshold {
+			continue
+		}
+
+		jump := ClockJump{At: now, Offset: offset}
+		Logger(ctx).Warn("wall clock jump detected",
+			"offset", offset,
+			"uptime", Uptime(),
+		)
+
+		if fn != nil {
+			fn(jump)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+	"time"
+)
+
+// GCConfig describes garbage collector tuning for latency-sensitive services.
+//
+// The runtime already honours the GOGC and GOMEMLIMIT environment variables;
+// GCConfig lets the same settings be applied in code.
+type GCConfig struct {
+	// Percent is the GOGC value to apply. Zero leaves the current value unchanged
+	// and a negative value disables the percentage-based trigger.
+	Percent int
+	// MemoryLimit is the soft memory limit in bytes. Zero leaves the current limit unchanged.
+	MemoryLimit int64
+}
+
+// GCPauseStats summarises the stop-the-world pauses caused by the garbage collector
+// since the process started.
+type GCPauseStats struct {
+	// Cycles is the number of completed GC cycles.
+	Cycles uint64
+	// P50, P99 and Max are approximations of the pause latency distribution,
+	// bounded by the resolution of the runtime's histogram buckets.
+	P50, P99, Max time.Duration
+}
+
+// TuneGC applies cfg to the runtime and returns a function that restores the previous settings.
+//
+// The pause statistics at the time of tuning are logged to the logger in ctx, so that
+// they can be compared with ReadGCPauseStats once the service has run for a while.
+func TuneGC(ctx context.Context, cfg GCConfig) (restore func()) {
+	var (
+		prevPercent int
+		prevLimit   int64
+	)
+	if cfg.Percent != 0 {
+		prevPercent = debug.SetGCPercent(cfg.Percent)
+	}
+	if cfg.MemoryLimit != 0 {
+		prevLimit = debug.SetMemoryLimit(cfg.MemoryLimit)
+	}
+
+	stats := ReadGCPauseStats()
+	Logger(ctx).Info("garbage collector tuned",
+		"gc_percent", cfg.Percent,
+		"memory_limit", cfg.MemoryLimit,
+		"gc_cycles", stats.Cycles,
+		"gc_pause_p50", stats.P50,
+		"gc_pause_p99", stats.P99,
+		"gc_pause_max", stats.Max,
+	)
+
+	return func() {
+		if cfg.Percent != 0 {
+			debug.SetGCPercent(prevPercent)
+		}
+		if cfg.MemoryLimit != 0 {
+			debug.SetMemoryLimit(prevLimit)
+		}
+	}
+}
+
+// RunIdleGC forces a garbage collection every interval while idle reports true,
+// until ctx is cancelled.
+//
+// Collecting during idle periods moves GC work away from request bursts.
+// If idle is nil, a collection is forced on every interval.
+func RunIdleGC(ctx context.Context, interval time.Duration, idle func() bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if idle == nil || idle() {
+				runtime.GC()
+			}
+		}
+	}
+}
+
+// ReadGCPauseStats returns the current GC pause statistics of the process.
+func ReadGCPauseStats() GCPauseStats {
+	samples := []metrics.Sample{
+		{Name: "/gc/cycles/total:gc-cycles"},
+		{Name: "/sched/pauses/total/gc:seconds"},
+	}
+	metrics.Read(samples)
+
+	var stats GCPauseStats
+	if samples[0].Value.Kind() == metrics.KindUint64 {
+		stats.Cycles = samples[0].Value.Uint64()
+	}
+	if samples[1].Value.Kind() == metrics.KindFloat64Histogram {
+		hist := samples[1].Value.Float64Histogram()
+		stats.P50 = histogramQuantile(hist, 0.5)
+		stats.P99 = histogramQuantile(hist, 0.99)
+		stats.Max = histogramQuantile(hist, 1)
+	}
+
+	return stats
+}
+
+// histogramQuantile returns the bucket bound at which the cumulative count of hist reaches q.
+func histogramQuantile(hist *metrics.Float64Histogram, q float64) time.Duration {
+	var total uint64
+	for _, count := range hist.Counts {
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+
+	target := uint64(math.Ceil(q * float64(total)))
+	var cumulative uint64
+	for i, count := range hist.Counts {
+		cumulative += count
+		if cumulative < target {
+			continue
+		}
+
+		// Buckets[i] and Buckets[i+1] are the bounds of bucket i; use the upper one
+		// unless it is unbounded.
+		bound := hist.Buckets[i+1]
+		if math.IsInf(bound, 1) {
+			bound = hist.Buckets[i]
+		}
+
+		return time.Duration(bound * float64(time.Second))
+	}
+
+	return 0
+}
@@ -2,13 +2,17 @@ module go.aledante.io/service
 
 go 1.25
 
+require (
+	github.com/fatih/color v1.18.0
+	github.com/klauspost/compress v1.20.1
+	github.com/mattn/go-isatty v0.0.20
+	go.opentelemetry.io/otel v1.38.0
+	go.opentelemetry.io/otel/trace v1.38.0
+	golang.org/x/sys v0.38.0
+)
+
 require (
 	github.com/DataDog/gostackparse v0.7.0 // indirect
-	github.com/fatih/color v1.18.0 // indirect
 	github.com/mattn/go-colorable v0.1.14 // indirect
-	github.com/mattn/go-isatty v0.0.20 // indirect
 	go.aledante.io/ae v0.0.13 // indirect
-	go.opentelemetry.io/otel v1.38.0 // indirect
-	go.opentelemetry.io/otel/trace v1.38.0 // indirect
-	golang.org/x/sys v0.38.0 // indirect
 )
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WriteHeartbeat writes the current time to the file at path every interval until
+// ctx is cancelled, then removes the file.
+//
+// Host-level agents can compare the timestamp with the file's age to detect a hung
+// process, independently of any HTTP health endpoint. The file is replaced atomically,
+// so readers never observe a partial write. Write failures are logged to the logger
+// in ctx and retried on the next interval.
+func WriteHeartbeat(ctx context.Context, path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := writeHeartbeat(path); err != nil {
+			Logger(ctx).Warn("failed to write heartbeat file", "path", path, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				Logger(ctx).Warn("failed to remove heartbeat file", "path", path, "error", err)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeHeartbeat atomically replaces the file at path with the current time in RFC 3339 format.
+func writeHeartbeat(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	// CreateTemp restricts the file to its owner; host agents need to read it.
+	err = tmp.Chmod(0o644)
+	if err == nil {
+		_, err = tmp.WriteString(time.Now().UTC().Format(time.RFC3339Nano) + "\n")
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	return nil
+}
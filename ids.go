@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"math/big"
+	mathrand "math/rand/v2"
+	"sync"
+	"time"
+)
+
+// IDFormat selects the format of the IDs generated by an IDSource.
+// All formats are time-ordered, so IDs sort roughly by creation time.
+type IDFormat int
+
+const (
+	// IDFormatUUIDv7 generates RFC 9562 version 7 UUIDs.
+	IDFormatUUIDv7 IDFormat = iota
+	// IDFormatULID generates ULIDs in Crockford base32.
+	IDFormatULID
+	// IDFormatKSUID generates KSUIDs in base62.
+	IDFormatKSUID
+)
+
+const (
+	// crockford is the alphabet used to encode ULIDs.
+	crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	// base62 is the alphabet used to encode KSUIDs.
+	base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	// ksuidEpoch is the KSUID epoch in Unix seconds.
+	ksuidEpoch = 1400000000
+	// ksuidLength is the length of an encoded KSUID.
+	ksuidLength = 27
+)
+
+// deterministicEpoch is the first timestamp used by deterministic ID sources.
+var deterministicEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// IDSource generates IDs for instances, requests, jobs and anything else that needs
+// a unique identifier, so that all IDs of a service share one format.
+//
+// It is safe for concurrent use.
+type IDSource struct {
+	format IDFormat
+
+	mu   sync.Mutex
+	rand io.Reader
+	now  func() time.Time
+}
+
+// NewIDSource returns an IDSource generating IDs of the given format from the
+// system clock and a cryptographically secure random source.
+func NewIDSource(format IDFormat) *IDSource {
+	return &IDSource{format: format, rand: rand.Reader, now: time.Now}
+}
+
+// NewDeterministicIDSource returns an IDSource that generates the same sequence of
+// IDs for the same seed, for use in tests.
+//
+// Its clock starts at a fixed time and advances by one millisecond per ID.
+func NewDeterministicIDSource(format IDFormat, seed uint64) *IDSource {
+	var key [32]byte
+	binary.LittleEndian.PutUint64(key[:], seed)
+
+	now := deterministicEpoch
+	return &IDSource{
+		format: format,
+		rand:   mathrand.NewChaCha8(key),
+		now: func() time.Time {
+			now = now.Add(time.Millisecond)
+			return now
+		},
+	}
+}
+
+// New returns a new ID.
+func (s *IDSource) New() string {
+	s.mu.Lock()
+	now := s.now()
+	var random [16]byte
+	_, _ = io.ReadFull(s.rand, random[:])
+	s.mu.Unlock()
+
+	switch s.format {
+	case IDFormatULID:
+		return encodeULID(now, random)
+	case IDFormatKSUID:
+		return encodeKSUID(now, random)
+	default:
+		return encodeUUIDv7(now, random)
+	}
+}
+
+// idSourceKey is an unexported type used as the key for storing the IDSource within context.Context.
+type idSourceKey struct{}
+
+// defaultIDSource is returned by IDs when ctx carries no IDSource.
+var defaultIDSource = NewIDSource(IDFormatUUIDv7)
+
+// WithIDSource returns a new context derived from ctx that carries the provided IDSource.
+//
+// The source can later be retrieved with IDs(ctx).
+func WithIDSource(ctx context.Context, src *IDSource) context.Context {
+	return context.WithValue(ctx, idSourceKey{}, src)
+}
+
+// IDs extracts the IDSource from ctx.
+//
+// If no source is found in ctx, it returns a process-wide source generating UUIDv7s.
+func IDs(ctx context.Context) *IDSource {
+	src, ok := ctx.Value(idSourceKey{}).(*IDSource)
+	if !ok {
+		return defaultIDSource
+	}
+
+	return src
+}
+
+// encodeUUIDv7 encodes a version 7 UUID from a timestamp and random bits.
+func encodeUUIDv7(now time.Time, random [16]byte) string {
+	var id [16]byte
+	ms := uint64(now.UnixMilli())
+	id[0], id[1], id[2] = byte(ms>>40), byte(ms>>32), byte(ms>>24)
+	id[3], id[4], id[5] = byte(ms>>16), byte(ms>>8), byte(ms)
+	copy(id[6:], random[:10])
+	id[6] = id[6]&0x0f | 0x70
+	id[8] = id[8]&0x3f | 0x80
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], id[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], id[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], id[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], id[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], id[10:])
+
+	return string(buf[:])
+}
+
+// encodeULID encodes a ULID from a timestamp and random bits.
+func encodeULID(now time.Time, random [16]byte) string {
+	var id [16]byte
+	ms := uint64(now.UnixMilli())
+	id[0], id[1], id[2] = byte(ms>>40), byte(ms>>32), byte(ms>>24)
+	id[3], id[4], id[5] = byte(ms>>16), byte(ms>>8), byte(ms)
+	copy(id[6:], random[:10])
+
+	// 128 bits in 26 characters of 5 bits each; the first character holds the top 3 bits.
+	hi, lo := binary.BigEndian.Uint64(id[:8]), binary.BigEndian.Uint64(id[8:])
+	var buf [26]byte
+	for i := 25; i >= 0; i-- {
+		buf[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+
+	return string(buf[:])
+}
+
+// encodeKSUID encodes a KSUID from a timestamp and random bits.
+func encodeKSUID(now time.Time, random [16]byte) string {
+	var id [20]byte
+	binary.BigEndian.PutUint32(id[:4], uint32(now.Unix()-ksuidEpoch))
+	copy(id[4:], random[:])
+
+	n := new(big.Int).SetBytes(id[:])
+	base := big.NewInt(62)
+	rem := new(big.Int)
+
+	var buf [ksuidLength]byte
+	for i := ksuidLength - 1; i >= 0; i-- {
+		n.DivMod(n, base, rem)
+		buf[i] = base62[rem.Int64()]
+	}
+
+	return string(buf[:])
+}
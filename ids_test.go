@@ -0,0 +1,191 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncodeUUIDv7(t *testing.T) {
+	now := time.UnixMilli(0x0123456789ab)
+
+	var random [16]byte
+	for i := range random {
+		random[i] = 0xff
+	}
+
+	id := encodeUUIDv7(now, random)
+
+	if len(id) != 36 {
+		t.Fatalf("got length %d, want 36: %s", len(id), id)
+	}
+	for _, i := range []int{8, 13, 18, 23} {
+		if id[i] != '-' {
+			t.Errorf("got %q at index %d, want '-': %s", id[i], i, id)
+		}
+	}
+	if !strings.HasPrefix(id, "01234567-89ab-") {
+		t.Errorf("timestamp not encoded in the first 48 bits: %s", id)
+	}
+	if id[14] != '7' {
+		t.Errorf("got version %q, want '7': %s", id[14], id)
+	}
+	if !strings.ContainsRune("89ab", rune(id[19])) {
+		t.Errorf("got variant %q, want one of 8, 9, a, b: %s", id[19], id)
+	}
+	if want := "01234567-89ab-7fff-bfff-ffffffffffff"; id != want {
+		t.Errorf("got %s, want %s", id, want)
+	}
+}
+
+func TestEncodeULID(t *testing.T) {
+	if got, want := encodeULID(time.UnixMilli(0), [16]byte{}), strings.Repeat("0", 26); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	var ones [16]byte
+	for i := range ones {
+		ones[i] = 0xff
+	}
+	if got, want := encodeULID(time.UnixMilli(1<<48-1), ones), "7"+strings.Repeat("Z", 25); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// The first ten characters encode the timestamp.
+	now := time.UnixMilli(1_700_000_000_123)
+	id := encodeULID(now, ones)
+
+	var ms int64
+	for _, c := range id[:10] {
+		ms = ms<<5 | int64(strings.IndexRune(crockford, c))
+	}
+	if ms != now.UnixMilli() {
+		t.Errorf("decoded timestamp %d, want %d", ms, now.UnixMilli())
+	}
+}
+
+func TestEncodeKSUID(t *testing.T) {
+	if got, want := encodeKSUID(time.Unix(ksuidEpoch, 0), [16]byte{}), strings.Repeat("0", ksuidLength); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	var ones [16]byte
+	for i := range ones {
+		ones[i] = 0xff
+	}
+	// The largest KSUID, with every bit set.
+	if got, want := encodeKSUID(time.Unix(ksuidEpoch+1<<32-1, 0), ones), "aWgEPTl1tmebfsQzFP4bxwgy80V"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	now := time.Unix(1_700_000_000, 0)
+	random := [16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	id := encodeKSUID(now, random)
+
+	n := new(big.Int)
+	for _, c := range id {
+		n.Mul(n, big.NewInt(62))
+		n.Add(n, big.NewInt(int64(strings.IndexRune(base62, c))))
+	}
+
+	var decoded [20]byte
+	n.FillBytes(decoded[:])
+
+	if ts := int64(decoded[0])<<24 | int64(decoded[1])<<16 | int64(decoded[2])<<8 | int64(decoded[3]); ts+ksuidEpoch != now.Unix() {
+		t.Errorf("decoded timestamp %d, want %d", ts+ksuidEpoch, now.Unix())
+	}
+	if !bytes.Equal(decoded[4:], random[:]) {
+		t.Errorf("decoded payload %x, want %x", decoded[4:], random)
+	}
+}
+
+func TestIDSourceFormats(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   IDFormat
+		length   int
+		alphabet string
+	}{
+		{name: "UUIDv7", format: IDFormatUUIDv7, length: 36, alphabet: "0123456789abcdef-"},
+		{name: "ULID", format: IDFormatULID, length: 26, alphabet: crockford},
+		{name: "KSUID", format: IDFormatKSUID, length: ksuidLength, alphabet: base62},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := NewIDSource(tt.format)
+
+			seen := make(map[string]bool)
+			for range 1000 {
+				id := src.New()
+				if len(id) != tt.length {
+					t.Fatalf("got length %d, want %d: %s", len(id), tt.length, id)
+				}
+				if i := strings.IndexFunc(id, func(c rune) bool { return !strings.ContainsRune(tt.alphabet, c) }); i >= 0 {
+					t.Fatalf("character %q outside the alphabet: %s", id[i], id)
+				}
+				if seen[id] {
+					t.Fatalf("duplicate ID %s", id)
+				}
+				seen[id] = true
+			}
+		})
+	}
+}
+
+func TestIDsAreTimeOrdered(t *testing.T) {
+	for _, format := range []IDFormat{IDFormatUUIDv7, IDFormatULID} {
+		src := NewDeterministicIDSource(format, 1)
+
+		ids := make([]string, 100)
+		for i := range ids {
+			ids[i] = src.New()
+		}
+
+		if !slices.IsSorted(ids) {
+			t.Errorf("format %d: IDs generated one millisecond apart are not sorted", format)
+		}
+	}
+
+	// KSUIDs have a resolution of one second.
+	var random [16]byte
+	random[0] = 0xff
+	earlier := encodeKSUID(time.Unix(1_700_000_000, 0), random)
+	later := encodeKSUID(time.Unix(1_700_000_001, 0), [16]byte{})
+	if earlier >= later {
+		t.Errorf("KSUID %s of an earlier second does not sort before %s", earlier, later)
+	}
+}
+
+func TestDeterministicIDSource(t *testing.T) {
+	for _, format := range []IDFormat{IDFormatUUIDv7, IDFormatULID, IDFormatKSUID} {
+		a := NewDeterministicIDSource(format, 42)
+		b := NewDeterministicIDSource(format, 42)
+		c := NewDeterministicIDSource(format, 43)
+
+		for i := range 10 {
+			idA, idB, idC := a.New(), b.New(), c.New()
+			if idA != idB {
+				t.Errorf("format %d, ID %d: same seed produced %s and %s", format, i, idA, idB)
+			}
+			if idA == idC {
+				t.Errorf("format %d, ID %d: different seeds produced %s", format, i, idA)
+			}
+		}
+	}
+}
+
+func TestIDsFromContext(t *testing.T) {
+	if IDs(context.Background()) != defaultIDSource {
+		t.Error("IDs did not return the default source for an empty context")
+	}
+
+	src := NewDeterministicIDSource(IDFormatULID, 1)
+	if IDs(WithIDSource(context.Background(), src)) != src {
+		t.Error("IDs did not return the source stored in the context")
+	}
+}
@@ -0,0 +1,26 @@
+package limiter
+
+import (
+	"net/http"
+)
+
+// Middleware returns an http.Handler that admits requests to next only while l has
+// capacity, answering the rest with 503 Service Unavailable.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		release, ok := l.Acquire()
+		if !ok {
+			reject(w)
+			return
+		}
+		defer release()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// reject answers a shed request with 503 Service Unavailable.
+func reject(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "1")
+	http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+}
@@ -0,0 +1,218 @@
+// Package limiter provides an adaptive concurrency limiter that sheds load when latency grows.
+//
+// The limit follows the gradient algorithm: it is scaled by the ratio between the
+// long-term and the most recent request latency, and grows by a small queue allowance
+// while latency is stable. This lets the limit track the service's actual capacity
+// without hand-tuned static values.
+package limiter
+
+import (
+	"maps"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	defaultInitialLimit = 20
+	defaultMinLimit     = 1
+	defaultMaxLimit     = 1000
+	defaultTolerance    = 1.5
+	defaultSmoothing    = 0.2
+
+	// longWindow is the number of samples the long-term latency average spans.
+	longWindow = 600
+)
+
+// Stats is a point-in-time snapshot of a Limiter.
+type Stats struct {
+	// Limit is the current concurrency limit.
+	Limit int
+	// InFlight is the number of acquired, not yet released slots.
+	InFlight int
+	// Rejected is the number of acquisitions refused, either because the share of the
+	// limit for their priority was reached or because their priority was being shed.
+	Rejected uint64
+}
+
+// Option configures a Limiter.
+type Option func(*Limiter)
+
+// WithInitialLimit sets the limit the Limiter starts with.
+func WithInitialLimit(limit int) Option {
+	return func(l *Limiter) {
+		l.limit = float64(limit)
+	}
+}
+
+// WithLimitBounds sets the range the adaptive limit is kept within.
+func WithLimitBounds(min, max int) Option {
+	return func(l *Limiter) {
+		l.minLimit = float64(min)
+		l.maxLimit = float64(max)
+	}
+}
+
+// WithTolerance sets how much the recent latency may exceed the long-term latency
+// before the limit is reduced. A tolerance of 2 allows latency to double.
+func WithTolerance(tolerance float64) Option {
+	return func(l *Limiter) {
+		l.tolerance = tolerance
+	}
+}
+
+// WithSmoothing sets the weight, in (0, 1], given to each new limit estimate.
+func WithSmoothing(smoothing float64) Option {
+	return func(l *Limiter) {
+		l.smoothing = smoothing
+	}
+}
+
+// WithPriorityShare sets the fraction, in (0, 1], of the limit that requests of
+// priority p may occupy. By default best-effort requests may use half of the
+// limit, normal requests 80% and critical requests all of it.
+func WithPriorityShare(p Priority, share float64) Option {
+	return func(l *Limiter) {
+		l.shares[p] = share
+	}
+}
+
+// Limiter is an adaptive concurrency limiter.
+//
+// It is safe for concurrent use.
+type Limiter struct {
+	mu        sync.Mutex
+	limit     float64
+	minLimit  float64
+	maxLimit  float64
+	tolerance float64
+	smoothing float64
+	longRTT   float64
+	inFlight  int
+	rejected  uint64
+	shares    map[Priority]float64
+	shedBelow Priority
+}
+
+// New returns a Limiter configured with opts.
+func New(opts ...Option) *Limiter {
+	l := &Limiter{
+		limit:     defaultInitialLimit,
+		minLimit:  defaultMinLimit,
+		maxLimit:  defaultMaxLimit,
+		tolerance: defaultTolerance,
+		smoothing: defaultSmoothing,
+		shares:    maps.Clone(defaultShares),
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	l.limit = l.clamp(l.limit)
+
+	return l
+}
+
+// Acquire reserves a slot for a PriorityCritical request, which by default
+// succeeds while fewer than Limit requests are in flight.
+//
+// If ok is true, release must be called exactly once when the request completes;
+// the time between Acquire and release is used as a latency sample.
+func (l *Limiter) Acquire() (release func(), ok bool) {
+	return l.AcquirePriority(PriorityCritical)
+}
+
+// AcquirePriority is like Acquire, but only admits requests of priority p while
+// the in-flight count is below p's share of the limit, so lower priorities are
+// shed first as the limit shrinks.
+//
+// Requests below the priority set with ShedBelow are always rejected, and
+// PriorityHealth requests are always admitted without counting towards the limit.
+func (l *Limiter) AcquirePriority(p Priority) (release func(), ok bool) {
+	if p >= PriorityHealth {
+		return func() {}, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if p < l.shedBelow || float64(l.inFlight) >= math.Max(1, math.Floor(l.limit*l.shares[p])) {
+		l.rejected++
+		return nil, false
+	}
+
+	l.inFlight++
+	start := time.Now()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.release(time.Since(start))
+		})
+	}, true
+}
+
+// ShedBelow makes the limiter reject every request with a priority lower than p,
+// regardless of capacity. It is meant for draining, where only critical work
+// should still be admitted. Passing PriorityBestEffort admits all priorities again.
+func (l *Limiter) ShedBelow(p Priority) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.shedBelow = p
+}
+
+// Stats returns a snapshot of the limiter's state.
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return Stats{
+		Limit:    int(l.limit),
+		InFlight: l.inFlight,
+		Rejected: l.rejected,
+	}
+}
+
+// release frees a slot and updates the limit from the latency sample rtt.
+func (l *Limiter) release(rtt time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+
+	sample := float64(rtt)
+	if sample <= 0 {
+		return
+	}
+
+	if l.longRTT == 0 {
+		l.longRTT = sample
+	} else {
+		l.longRTT += (sample - l.longRTT) / longWindow
+	}
+
+	// Let the long-term average recover quickly after a sustained latency drop,
+	// otherwise the limit would keep growing unchecked.
+	if l.longRTT/sample > 2 {
+		l.longRTT *= 0.95
+	}
+
+	// Only adapt when the limit is actually being exercised; an idle service
+	// says nothing about its capacity.
+	if float64(l.inFlight+1) < l.limit/2 {
+		return
+	}
+
+	gradient := math.Max(0.5, math.Min(1, l.tolerance*l.longRTT/sample))
+	queue := math.Sqrt(l.limit)
+	estimate := l.limit*gradient + queue
+
+	l.limit = l.clamp(l.limit*(1-l.smoothing) + estimate*l.smoothing)
+}
+
+// clamp keeps limit within the configured bounds.
+func (l *Limiter) clamp(limit float64) float64 {
+	return math.Max(l.minLimit, math.Min(l.maxLimit, limit))
+}
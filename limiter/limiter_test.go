@@ -0,0 +1,83 @@
+package limiter
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// sample feeds the latency rtt to l as if it had been measured with the limit fully used,
+// so that the app-limited guard does not skip the update.
+func sample(l *Limiter, rtt time.Duration) {
+	l.mu.Lock()
+	l.inFlight = int(math.Ceil(l.limit))
+	l.mu.Unlock()
+
+	l.release(rtt)
+}
+
+func TestLimiterAdaptsToLatency(t *testing.T) {
+	l := New(WithInitialLimit(20))
+
+	for range 50 {
+		sample(l, 10*time.Millisecond)
+	}
+	stable := l.Stats().Limit
+	if stable <= 20 {
+		t.Fatalf("limit is %d after stable latency, want it to grow beyond 20", stable)
+	}
+
+	for range 20 {
+		sample(l, 50*time.Millisecond)
+	}
+	congested := l.Stats().Limit
+	if congested >= stable/2 {
+		t.Errorf("limit is %d after latency rose fivefold, want it below %d", congested, stable/2)
+	}
+
+	for range 50 {
+		sample(l, 10*time.Millisecond)
+	}
+	if recovered := l.Stats().Limit; recovered <= congested {
+		t.Errorf("limit is %d after latency stabilised again, want it above %d", recovered, congested)
+	}
+}
+
+func TestLimiterIgnoresSamplesWhenAppLimited(t *testing.T) {
+	l := New(WithInitialLimit(20))
+
+	// With a single request in flight, the limit is far from being exercised.
+	for range 50 {
+		l.mu.Lock()
+		l.inFlight = 1
+		l.mu.Unlock()
+
+		l.release(time.Second)
+	}
+
+	if limit := l.Stats().Limit; limit != 20 {
+		t.Errorf("limit is %d after samples taken while app-limited, want 20", limit)
+	}
+}
+
+func TestLimiterClampsLimit(t *testing.T) {
+	if limit := New(WithInitialLimit(100), WithLimitBounds(5, 30)).Stats().Limit; limit != 30 {
+		t.Errorf("initial limit is %d, want it clamped to 30", limit)
+	}
+
+	l := New(WithInitialLimit(10), WithLimitBounds(5, 30))
+
+	for range 200 {
+		sample(l, 10*time.Millisecond)
+	}
+	if limit := l.Stats().Limit; limit != 30 {
+		t.Errorf("limit is %d after stable latency, want the maximum 30", limit)
+	}
+
+	for range 200 {
+		sample(l, time.Second)
+	}
+	if limit := l.Stats().Limit; limit != 5 {
+		t.Errorf("limit is %d after high latency, want the minimum 5", limit)
+	}
+}
@@ -0,0 +1,94 @@
+package limiter
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Priority classifies requests for load shedding. Lower priorities are shed first.
+type Priority int
+
+const (
+	// PriorityBestEffort marks requests that may be dropped whenever the service is under pressure.
+	PriorityBestEffort Priority = iota
+	// PriorityNormal marks regular traffic.
+	PriorityNormal
+	// PriorityCritical marks requests that are only shed once the full limit is reached.
+	PriorityCritical
+	// PriorityHealth marks health checks, which are never shed.
+	PriorityHealth
+)
+
+// defaultShares is the fraction of the limit each priority may occupy by default.
+var defaultShares = map[Priority]float64{
+	PriorityBestEffort: 0.5,
+	PriorityNormal:     0.8,
+	PriorityCritical:   1,
+}
+
+// Rule assigns a Priority to the requests it matches.
+//
+// A rule matches when every non-empty condition holds.
+type Rule struct {
+	// PathPrefix matches requests whose URL path starts with the prefix.
+	PathPrefix string
+	// Header matches requests carrying the header. If Value is non-empty,
+	// the header must also have exactly that value.
+	Header string
+	Value  string
+	// Priority is assigned to matching requests.
+	Priority Priority
+}
+
+// matches reports whether r satisfies every condition of the rule.
+func (rule Rule) matches(r *http.Request) bool {
+	if rule.PathPrefix != "" && !strings.HasPrefix(r.URL.Path, rule.PathPrefix) {
+		return false
+	}
+
+	if rule.Header != "" {
+		values, ok := r.Header[http.CanonicalHeaderKey(rule.Header)]
+		if !ok {
+			return false
+		}
+		if rule.Value != "" && (len(values) == 0 || values[0] != rule.Value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Classifier assigns priorities to HTTP requests using an ordered list of rules.
+type Classifier struct {
+	// Rules are evaluated in order; the first matching rule wins.
+	Rules []Rule
+	// Default is assigned to requests no rule matches.
+	Default Priority
+}
+
+// Classify returns the priority of r.
+func (c Classifier) Classify(r *http.Request) Priority {
+	for _, rule := range c.Rules {
+		if rule.matches(r) {
+			return rule.Priority
+		}
+	}
+
+	return c.Default
+}
+
+// PriorityMiddleware is like Middleware, but classifies each request with c and
+// admits it according to its priority.
+func (l *Limiter) PriorityMiddleware(c Classifier, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		release, ok := l.AcquirePriority(c.Classify(r))
+		if !ok {
+			reject(w)
+			return
+		}
+		defer release()
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,180 @@
+package limiter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClassifier(t *testing.T) {
+	c := Classifier{
+		Rules: []Rule{
+			{PathPrefix: "/healthz", Priority: PriorityHealth},
+			{PathPrefix: "/admin", Header: "X-Role", Value: "operator", Priority: PriorityCritical},
+			{Header: "X-Batch", Priority: PriorityBestEffort},
+			{PathPrefix: "/api", Priority: PriorityNormal},
+		},
+		Default: PriorityBestEffort,
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		headers map[string]string
+		want    Priority
+	}{
+		{name: "path prefix", path: "/healthz/live", want: PriorityHealth},
+		{name: "path and header value", path: "/admin/drain", headers: map[string]string{"X-Role": "operator"}, want: PriorityCritical},
+		{name: "header value mismatch", path: "/admin/drain", headers: map[string]string{"X-Role": "viewer"}, want: PriorityBestEffort},
+		{name: "header presence", path: "/api/items", headers: map[string]string{"x-batch": ""}, want: PriorityBestEffort},
+		{name: "first match wins", path: "/api/items", headers: map[string]string{"X-Batch": "1"}, want: PriorityBestEffort},
+		{name: "later rule", path: "/api/items", want: PriorityNormal},
+		{name: "default", path: "/other", want: PriorityBestEffort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+
+			if got := c.Classify(r); got != tt.want {
+				t.Errorf("got priority %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// admitted acquires slots of priority p from l until one is rejected and returns
+// how many were admitted. The slots are not released.
+func admitted(l *Limiter, p Priority) int {
+	n := 0
+	for ; n < 1000; n++ {
+		if _, ok := l.AcquirePriority(p); !ok {
+			break
+		}
+	}
+
+	return n
+}
+
+func TestLimiterPriorityShares(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		priority Priority
+		want     int
+	}{
+		{name: "best effort", priority: PriorityBestEffort, want: 5},
+		{name: "normal", priority: PriorityNormal, want: 8},
+		{name: "critical", priority: PriorityCritical, want: 10},
+		{name: "custom share", opts: []Option{WithPriorityShare(PriorityNormal, 0.3)}, priority: PriorityNormal, want: 3},
+		// The share is floored at one slot, so a priority is never locked out entirely.
+		{name: "zero share", opts: []Option{WithPriorityShare(PriorityBestEffort, 0)}, priority: PriorityBestEffort, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(append([]Option{WithInitialLimit(10)}, tt.opts...)...)
+
+			if got := admitted(l, tt.priority); got != tt.want {
+				t.Errorf("admitted %d requests, want %d", got, tt.want)
+			}
+			if rejected := l.Stats().Rejected; rejected != 1 {
+				t.Errorf("got %d rejections, want 1", rejected)
+			}
+		})
+	}
+}
+
+func TestLimiterHigherPrioritiesUseRemainingCapacity(t *testing.T) {
+	l := New(WithInitialLimit(10))
+
+	if got := admitted(l, PriorityBestEffort); got != 5 {
+		t.Fatalf("admitted %d best-effort requests, want 5", got)
+	}
+	if got := admitted(l, PriorityNormal); got != 3 {
+		t.Errorf("admitted %d normal requests on top, want 3", got)
+	}
+	if got := admitted(l, PriorityCritical); got != 2 {
+		t.Errorf("admitted %d critical requests on top, want 2", got)
+	}
+}
+
+func TestLimiterShedBelow(t *testing.T) {
+	l := New(WithInitialLimit(10))
+	l.ShedBelow(PriorityCritical)
+
+	for _, p := range []Priority{PriorityBestEffort, PriorityNormal} {
+		if _, ok := l.AcquirePriority(p); ok {
+			t.Errorf("priority %d admitted while shedding below critical", p)
+		}
+	}
+
+	release, ok := l.AcquirePriority(PriorityCritical)
+	if !ok {
+		t.Fatal("critical request rejected while shedding below critical")
+	}
+	release()
+
+	l.ShedBelow(PriorityBestEffort)
+	if _, ok := l.AcquirePriority(PriorityBestEffort); !ok {
+		t.Error("best-effort request rejected after shedding was lifted")
+	}
+}
+
+func TestLimiterHealthBypass(t *testing.T) {
+	l := New(WithInitialLimit(2))
+	l.ShedBelow(PriorityHealth)
+
+	if got := admitted(l, PriorityCritical); got != 0 {
+		t.Fatalf("admitted %d critical requests while shedding below health, want 0", got)
+	}
+
+	l.ShedBelow(PriorityBestEffort)
+	admitted(l, PriorityCritical)
+
+	for range 10 {
+		release, ok := l.AcquirePriority(PriorityHealth)
+		if !ok {
+			t.Fatal("health check rejected with the limit reached")
+		}
+		release()
+	}
+
+	if inFlight := l.Stats().InFlight; inFlight != 2 {
+		t.Errorf("got %d in flight, want health checks not to count", inFlight)
+	}
+}
+
+func TestPriorityMiddleware(t *testing.T) {
+	l := New(WithInitialLimit(10))
+	l.ShedBelow(PriorityCritical)
+
+	c := Classifier{
+		Rules:   []Rule{{PathPrefix: "/healthz", Priority: PriorityHealth}},
+		Default: PriorityNormal,
+	}
+	h := l.PriorityMiddleware(c, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{path: "/healthz", wantStatus: http.StatusOK},
+		{path: "/api", wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: got status %d, want %d", tt.path, w.Code, tt.wantStatus)
+		}
+		if tt.wantStatus == http.StatusServiceUnavailable && w.Header().Get("Retry-After") == "" {
+			t.Errorf("%s: rejected without Retry-After", tt.path)
+		}
+	}
+}
@@ -0,0 +1,555 @@
+package service
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression selects how rotated log files are compressed.
+type Compression string
+
+const (
+	// CompressionNone leaves rotated log files uncompressed.
+	CompressionNone Compression = "none"
+	// CompressionGzip compresses rotated log files with gzip.
+	CompressionGzip Compression = "gzip"
+	// CompressionZstd compresses rotated log files with zstd.
+	CompressionZstd Compression = "zstd"
+)
+
+// SyncPolicy selects when a LogFile is flushed to stable storage.
+type SyncPolicy string
+
+const (
+	// SyncNever leaves flushing to the operating system.
+	SyncNever SyncPolicy = "never"
+	// SyncOnRotate syncs the file before it is rotated or closed.
+	SyncOnRotate SyncPolicy = "rotate"
+	// SyncAlways syncs the file after every write.
+	SyncAlways SyncPolicy = "always"
+)
+
+// rotatedTimeFormat is the timestamp format inserted into the names of rotated log files.
+const rotatedTimeFormat = "2006-01-02T15-04-05.000"
+
+// LogFileConfig configures a LogFile.
+type LogFileConfig struct {
+	// Path is the path of the active log file.
+	Path string
+	// MaxSize is the size in bytes at which the file is rotated. Zero disables size-based rotation.
+	MaxSize int64
+	// RotateEvery is the age at which the file is rotated. Zero disables time-based rotation.
+	RotateEvery time.Duration
+	// MaxAge is the age after which rotated files are deleted. Zero keeps them regardless of age.
+	MaxAge time.Duration
+	// MaxBackups is the number of rotated files kept. Zero keeps all of them.
+	MaxBackups int
+	// Compression is applied to rotated files. The empty value means CompressionNone.
+	Compression Compression
+	// Sync selects when the file is synced. The empty value means SyncNever.
+	Sync SyncPolicy
+}
+
+// LogFileConfigFromEnv reads a LogFileConfig from the environment:
+//
+//   - LOG_FILE_PATH: path of the active log file
+//   - LOG_FILE_MAX_SIZE_MB: size in megabytes at which the file is rotated
+//   - LOG_FILE_ROTATE_EVERY: age at which the file is rotated, as a Go duration
+//   - LOG_FILE_MAX_AGE: age after which rotated files are deleted, as a Go duration
+//   - LOG_FILE_MAX_BACKUPS: number of rotated files kept
+//   - LOG_FILE_COMPRESSION: none, gzip or zstd
+//   - LOG_FILE_SYNC: never, rotate or always
+//
+// Unset variables leave the corresponding field at its zero value.
+func LogFileConfigFromEnv() (LogFileConfig, error) {
+	cfg := LogFileConfig{
+		Path:        os.Getenv("LOG_FILE_PATH"),
+		Compression: Compression(strings.ToLower(os.Getenv("LOG_FILE_COMPRESSION"))),
+		Sync:        SyncPolicy(strings.ToLower(os.Getenv("LOG_FILE_SYNC"))),
+	}
+
+	if v := os.Getenv("LOG_FILE_MAX_SIZE_MB"); v != "" {
+		mb, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid LOG_FILE_MAX_SIZE_MB: %w", err)
+		}
+		cfg.MaxSize = mb << 20
+	}
+
+	if v := os.Getenv("LOG_FILE_ROTATE_EVERY"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid LOG_FILE_ROTATE_EVERY: %w", err)
+		}
+		cfg.RotateEvery = d
+	}
+
+	if v := os.Getenv("LOG_FILE_MAX_AGE"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid LOG_FILE_MAX_AGE: %w", err)
+		}
+		cfg.MaxAge = d
+	}
+
+	if v := os.Getenv("LOG_FILE_MAX_BACKUPS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid LOG_FILE_MAX_BACKUPS: %w", err)
+		}
+		cfg.MaxBackups = n
+	}
+
+	return cfg, nil
+}
+
+// LogFile is an io.WriteCloser writing to a file that is rotated by size and age.
+//
+// Rotated files are renamed with a timestamp inserted before the extension, followed
+// by a counter if several rotations happen within the same millisecond, then
+// compressed and pruned according to the retention settings on a background goroutine.
+// It is safe for concurrent use.
+type LogFile struct {
+	cfg LogFileConfig
+
+	mu sync.Mutex
+	// file is the active file. It is nil after a failed rotation left no file open,
+	// in which case the next write reopens it.
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	closed   bool
+
+	// wake signals the mill that rotated files were queued; it is closed by Close.
+	wake chan struct{}
+	done chan struct{}
+
+	// queue holds the rotated files waiting for the mill, oldest first. pending holds
+	// the same files plus the one being compressed, which prune must not remove.
+	pendingMu sync.Mutex
+	queue     []string
+	pending   map[string]struct{}
+}
+
+// OpenLogFile opens or creates the log file described by cfg, appending to any existing content.
+func OpenLogFile(cfg LogFileConfig) (*LogFile, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("log file path is empty")
+	}
+
+	switch cfg.Compression {
+	case "":
+		cfg.Compression = CompressionNone
+	case CompressionNone, CompressionGzip, CompressionZstd:
+	default:
+		return nil, fmt.Errorf("unknown log file compression %q", cfg.Compression)
+	}
+
+	switch cfg.Sync {
+	case "":
+		cfg.Sync = SyncNever
+	case SyncNever, SyncOnRotate, SyncAlways:
+	default:
+		return nil, fmt.Errorf("unknown log file sync policy %q", cfg.Sync)
+	}
+
+	f := &LogFile{
+		cfg:     cfg,
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		pending: make(map[string]struct{}),
+	}
+
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	go f.mill()
+
+	return f, nil
+}
+
+// Write writes p to the log file, rotating it first if the write would exceed
+// MaxSize or the file is older than RotateEvery.
+//
+// A failed rotation is reported to stderr and does not fail the write; p is
+// written to the reopened active file instead, so that logging recovers.
+func (f *LogFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+
+	if f.file != nil && f.shouldRotate(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "rotate log file %s: %v\n", f.cfg.Path, err)
+		}
+	}
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+
+	if f.cfg.Sync == SyncAlways {
+		if err := f.file.Sync(); err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
+
+// Rotate closes the active file, renames it and starts a new one.
+func (f *LogFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+
+	if f.file == nil {
+		return f.open()
+	}
+
+	return f.rotate()
+}
+
+// Close closes the active file and waits for pending compression and pruning to finish.
+func (f *LogFile) Close() error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return os.ErrClosed
+	}
+
+	var err error
+	if f.file != nil {
+		err = f.closeFile()
+	}
+	f.closed = true
+	close(f.wake)
+	f.mu.Unlock()
+
+	<-f.done
+
+	return err
+}
+
+// shouldRotate reports whether the active file must be rotated before writing n bytes.
+// The caller must hold f.mu.
+func (f *LogFile) shouldRotate(n int64) bool {
+	if f.cfg.MaxSize > 0 && f.size > 0 && f.size+n > f.cfg.MaxSize {
+		return true
+	}
+
+	return f.cfg.RotateEvery > 0 && time.Since(f.openedAt) >= f.cfg.RotateEvery
+}
+
+// rotate renames the active file, opens a new one and queues the old one for the mill.
+// Queueing never blocks, so writes do not wait for compression.
+//
+// The active file is reopened even if closing or renaming fails, so a single failure
+// does not leave the LogFile without a file. If the active file was removed
+// externally, there is nothing to rotate and a new file is started.
+// The caller must hold f.mu.
+func (f *LogFile) rotate() error {
+	closeErr := f.closeFile()
+	f.file = nil
+
+	name := f.backupName()
+	renameErr := os.Rename(f.cfg.Path, name)
+	if errors.Is(renameErr, os.ErrNotExist) {
+		renameErr = nil
+		name = ""
+	} else if renameErr != nil {
+		renameErr = fmt.Errorf("rename log file: %w", renameErr)
+		name = ""
+	}
+
+	if err := f.open(); err != nil {
+		return errors.Join(closeErr, renameErr, err)
+	}
+
+	if name != "" {
+		f.pendingMu.Lock()
+		f.queue = append(f.queue, name)
+		f.pending[name] = struct{}{}
+		f.pendingMu.Unlock()
+
+		select {
+		case f.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	return errors.Join(closeErr, renameErr)
+}
+
+// backupName returns an unused name for the next rotated file. If a file rotated
+// within the same millisecond exists, compressed or not, a counter is appended to
+// the timestamp.
+func (f *LogFile) backupName() string {
+	path := filepath.Clean(f.cfg.Path)
+	ext := filepath.Ext(path)
+	base := fmt.Sprintf("%s-%s", strings.TrimSuffix(path, ext), time.Now().UTC().Format(rotatedTimeFormat))
+
+	name := base + ext
+	for i := 1; backupExists(name); i++ {
+		name = fmt.Sprintf("%s.%d%s", base, i, ext)
+	}
+
+	return name
+}
+
+// backupExists reports whether a rotated file named name exists, in any compression.
+func backupExists(name string) bool {
+	for _, suffix := range []string{"", ".gz", ".zst"} {
+		if _, err := os.Lstat(name + suffix); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// open opens the file at the configured path for appending. The caller must hold f.mu.
+func (f *LogFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.cfg.Path), 0o755); err != nil {
+		return fmt.Errorf("create log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(f.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("stat log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = time.Now()
+
+	// An existing file keeps its age across restarts, so that RotateEvery is honoured
+	// by services restarting more often than it.
+	if f.size > 0 {
+		if created := fileCreated(f.cfg.Path, info); created.Before(f.openedAt) {
+			f.openedAt = created
+		}
+	}
+
+	return nil
+}
+
+// closeFile syncs the active file if the policy asks for it and closes it.
+// The caller must hold f.mu.
+func (f *LogFile) closeFile() error {
+	if f.cfg.Sync != SyncNever {
+		if err := f.file.Sync(); err != nil {
+			_ = f.file.Close()
+			return fmt.Errorf("sync log file: %w", err)
+		}
+	}
+
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("close log file: %w", err)
+	}
+
+	return nil
+}
+
+// mill compresses rotated files and prunes old ones until the LogFile is closed.
+//
+// Failures are reported to stderr, as the log file itself may be what is failing.
+func (f *LogFile) mill() {
+	defer close(f.done)
+
+	for {
+		_, ok := <-f.wake
+
+		for {
+			f.pendingMu.Lock()
+			if len(f.queue) == 0 {
+				f.pendingMu.Unlock()
+				break
+			}
+			name := f.queue[0]
+			f.queue = f.queue[1:]
+			f.pendingMu.Unlock()
+
+			if err := compressLogFile(name, f.cfg.Compression); err != nil {
+				fmt.Fprintf(os.Stderr, "compress rotated log file %s: %v\n", name, err)
+			}
+
+			f.pendingMu.Lock()
+			delete(f.pending, name)
+			f.pendingMu.Unlock()
+
+			if err := f.prune(); err != nil {
+				fmt.Fprintf(os.Stderr, "prune rotated log files: %v\n", err)
+			}
+		}
+
+		if !ok {
+			return
+		}
+	}
+}
+
+// prune deletes rotated files beyond MaxBackups or older than MaxAge.
+//
+// Files still waiting to be compressed count towards MaxBackups but are never
+// deleted; they are pruned on a later pass, once the mill has processed them.
+func (f *LogFile) prune() error {
+	if f.cfg.MaxBackups <= 0 && f.cfg.MaxAge <= 0 {
+		return nil
+	}
+
+	ext := filepath.Ext(f.cfg.Path)
+	prefix := filepath.Base(strings.TrimSuffix(f.cfg.Path, ext)) + "-"
+	dir := filepath.Dir(f.cfg.Path)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	type backup struct {
+		name string
+		at   time.Time
+		seq  int
+	}
+
+	var backups []backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		stamp := strings.TrimPrefix(name, prefix)
+		if len(stamp) < len(rotatedTimeFormat) {
+			continue
+		}
+
+		at, err := time.Parse(rotatedTimeFormat, stamp[:len(rotatedTimeFormat)])
+		if err != nil {
+			continue
+		}
+
+		// Files rotated within the same millisecond carry a counter: name-<ts>.<n><ext>.
+		var seq int
+		if rest, ok := strings.CutPrefix(stamp[len(rotatedTimeFormat):], "."); ok {
+			digits, _, _ := strings.Cut(rest, ".")
+			seq, _ = strconv.Atoi(digits)
+		}
+
+		backups = append(backups, backup{name: name, at: at, seq: seq})
+	}
+
+	// Newest first, so that the backups to keep form a prefix.
+	slices.SortFunc(backups, func(a, b backup) int {
+		if c := b.at.Compare(a.at); c != 0 {
+			return c
+		}
+
+		return b.seq - a.seq
+	})
+
+	var errs []error
+	for i, b := range backups {
+		expired := f.cfg.MaxAge > 0 && time.Since(b.at) > f.cfg.MaxAge
+		excess := f.cfg.MaxBackups > 0 && i >= f.cfg.MaxBackups
+		if !expired && !excess {
+			continue
+		}
+
+		f.pendingMu.Lock()
+		_, pending := f.pending[filepath.Join(dir, b.name)]
+		f.pendingMu.Unlock()
+		if pending {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(dir, b.name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// compressLogFile replaces the file at name with a compressed copy.
+func compressLogFile(name string, compression Compression) (err error) {
+	var suffix string
+	switch compression {
+	case CompressionGzip:
+		suffix = ".gz"
+	case CompressionZstd:
+		suffix = ".zst"
+	default:
+		return nil
+	}
+
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+suffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = dst.Close()
+			_ = os.Remove(name + suffix)
+		}
+	}()
+
+	var enc io.WriteCloser
+	if compression == CompressionGzip {
+		enc = gzip.NewWriter(dst)
+	} else if enc, err = zstd.NewWriter(dst); err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(enc, src); err != nil {
+		_ = enc.Close()
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(name)
+}
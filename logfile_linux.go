@@ -0,0 +1,19 @@
+package service
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// fileCreated returns the creation time of the file at path, falling back to the
+// modification time in info if the file system does not record it.
+func fileCreated(path string, info os.FileInfo) time.Time {
+	var stx unix.Statx_t
+	if err := unix.Statx(unix.AT_FDCWD, path, 0, unix.STATX_BTIME, &stx); err != nil || stx.Mask&unix.STATX_BTIME == 0 {
+		return info.ModTime()
+	}
+
+	return time.Unix(stx.Btime.Sec, int64(stx.Btime.Nsec))
+}
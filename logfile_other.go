@@ -0,0 +1,14 @@
+//go:build !linux
+
+package service
+
+import (
+	"os"
+	"time"
+)
+
+// fileCreated returns the modification time in info, the closest portable
+// approximation of the creation time of the file at path.
+func fileCreated(_ string, info os.FileInfo) time.Time {
+	return info.ModTime()
+}
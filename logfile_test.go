@@ -0,0 +1,194 @@
+package service
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// captureStderr redirects os.Stderr while fn runs and returns what was written to it.
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+
+	out := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, r)
+		out <- buf.String()
+	}()
+
+	fn()
+
+	_ = w.Close()
+	return <-out
+}
+
+// dirSize returns the number of files in dir and their total size.
+func dirSize(t *testing.T, dir string) (int, int64) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var total int64
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += info.Size()
+	}
+
+	return len(entries), total
+}
+
+func TestLogFileRotationWithinSameMillisecond(t *testing.T) {
+	dir := t.TempDir()
+
+	f, err := OpenLogFile(LogFileConfig{Path: filepath.Join(dir, "app.log"), MaxSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line := []byte("0123456789abcdef\n")
+	for range 50 {
+		if _, err := f.Write(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, size := dirSize(t, dir)
+	if files != 50 {
+		t.Errorf("got %d files, want 50", files)
+	}
+	if want := int64(50 * len(line)); size != want {
+		t.Errorf("got %d bytes on disk, want %d", size, want)
+	}
+}
+
+func TestLogFileRecoversFromRemovedFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	f, err := OpenLogFile(LogFileConfig{Path: path, MaxSize: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.Write([]byte("first line\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both writes exceed MaxSize and rotate; the first finds nothing to rename.
+	for _, line := range []string{"second line\n", "third line\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("write after removal: %v", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "third line\n" {
+		t.Errorf("active file contains %q, want %q", data, "third line\n")
+	}
+}
+
+func TestLogFileRetention(t *testing.T) {
+	dir := t.TempDir()
+
+	stderr := captureStderr(t, func() {
+		f, err := OpenLogFile(LogFileConfig{
+			Path:        filepath.Join(dir, "app.log"),
+			MaxSize:     10,
+			MaxBackups:  2,
+			Compression: CompressionGzip,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for range 30 {
+			if _, err := f.Write([]byte("0123456789abcdef\n")); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if stderr != "" {
+		t.Errorf("unexpected stderr output: %s", stderr)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		if entry.Name() == "app.log" {
+			continue
+		}
+		if !strings.HasSuffix(entry.Name(), ".log.gz") {
+			t.Errorf("backup %s is not compressed", entry.Name())
+		}
+		backups = append(backups, entry.Name())
+	}
+
+	if len(backups) != 2 {
+		t.Errorf("got backups %v, want 2", backups)
+	}
+}
+
+func TestLogFileRotateEveryAcrossReopen(t *testing.T) {
+	dir := t.TempDir()
+	cfg := LogFileConfig{Path: filepath.Join(dir, "app.log"), RotateEvery: 50 * time.Millisecond}
+
+	// Each run is shorter than RotateEvery, but together they exceed it.
+	for range 4 {
+		f, err := OpenLogFile(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte("line\n")); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if files, _ := dirSize(t, dir); files < 2 {
+		t.Errorf("got %d files, want the active file and at least one backup", files)
+	}
+}
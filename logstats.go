@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// LogStats is a point-in-time snapshot of the counters kept by a CountingHandler.
+type LogStats struct {
+	// Debug, Info, Warn and Error count the records handled at each level.
+	// Records with custom levels are attributed to the nearest standard level below them.
+	Debug, Info, Warn, Error uint64
+	// Suppressed counts calls to Enabled that reported a level as disabled.
+	// slog.Logger makes one such call per logging call, so for records logged
+	// through a Logger this is the number dropped by level; code that calls
+	// Enabled directly, for example to skip expensive work, adds to it as well.
+	Suppressed uint64
+	// HandlerErrors counts records the wrapped handler failed to handle.
+	HandlerErrors uint64
+}
+
+// logCounters holds the counters shared between a CountingHandler and all handlers derived from it.
+type logCounters struct {
+	debug, info, warn, error atomic.Uint64
+	suppressed               atomic.Uint64
+	handlerErrors            atomic.Uint64
+}
+
+// CountingHandler is a slog.Handler that counts the records passing through it
+// before delegating to the wrapped handler.
+//
+// Handlers derived through WithAttrs and WithGroup share the counters of their parent,
+// so Stats always reports totals for the whole logging pipeline.
+type CountingHandler struct {
+	next     slog.Handler
+	counters *logCounters
+}
+
+// NewCountingHandler returns a CountingHandler that delegates to next.
+func NewCountingHandler(next slog.Handler) *CountingHandler {
+	return &CountingHandler{next: next, counters: &logCounters{}}
+}
+
+// Stats returns a snapshot of the counters collected so far.
+func (h *CountingHandler) Stats() LogStats {
+	return LogStats{
+		Debug:         h.counters.debug.Load(),
+		Info:          h.counters.info.Load(),
+		Warn:          h.counters.warn.Load(),
+		Error:         h.counters.error.Load(),
+		Suppressed:    h.counters.suppressed.Load(),
+		HandlerErrors: h.counters.handlerErrors.Load(),
+	}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+//
+// Every call that reports the level as disabled is counted as suppressed,
+// whether or not a record would have followed it.
+func (h *CountingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if !h.next.Enabled(ctx, level) {
+		h.counters.suppressed.Add(1)
+		return false
+	}
+
+	return true
+}
+
+// Handle counts r by level and passes it to the wrapped handler,
+// counting any error the wrapped handler returns.
+func (h *CountingHandler) Handle(ctx context.Context, r slog.Record) error {
+	switch {
+	case r.Level >= slog.LevelError:
+		h.counters.error.Add(1)
+	case r.Level >= slog.LevelWarn:
+		h.counters.warn.Add(1)
+	case r.Level >= slog.LevelInfo:
+		h.counters.info.Add(1)
+	default:
+		h.counters.debug.Add(1)
+	}
+
+	if err := h.next.Handle(ctx, r); err != nil {
+		h.counters.handlerErrors.Add(1)
+		return err
+	}
+
+	return nil
+}
+
+// WithAttrs returns a CountingHandler sharing the counters of h that wraps next.WithAttrs(attrs).
+func (h *CountingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &CountingHandler{next: h.next.WithAttrs(attrs), counters: h.counters}
+}
+
+// WithGroup returns a CountingHandler sharing the counters of h that wraps next.WithGroup(name).
+func (h *CountingHandler) WithGroup(name string) slog.Handler {
+	return &CountingHandler{next: h.next.WithGroup(name), counters: h.counters}
+}
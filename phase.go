@@ -1,5 +1,13 @@
 package service
 
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
 // Phase represents the lifecycle phase of the service.
 type Phase string
 
@@ -10,6 +18,9 @@ const (
 	PhaseInitializing Phase = "INITIALIZING"
 	// PhaseRunning indicates the service is actively running.
 	PhaseRunning Phase = "RUNNING"
+	// PhaseDegraded indicates the service is running with reduced functionality,
+	// for example because an optional dependency is unavailable.
+	PhaseDegraded Phase = "DEGRADED"
 	// PhaseStopping indicates the service is in the process of stopping.
 	PhaseStopping Phase = "STOPPING"
 	// PhaseStopped indicates the service has stopped.
@@ -17,3 +28,158 @@ const (
 	// PhaseError indicates the service has stopped due to an error.
 	PhaseError Phase = "ERROR"
 )
+
+// phaseTransitions lists the phases each phase may move to.
+// PhaseStopped and PhaseError are terminal.
+var phaseTransitions = map[Phase][]Phase{
+	PhaseWaiting:      {PhaseInitializing, PhaseStopping, PhaseError},
+	PhaseInitializing: {PhaseRunning, PhaseStopping, PhaseError},
+	PhaseRunning:      {PhaseDegraded, PhaseStopping, PhaseError},
+	PhaseDegraded:     {PhaseRunning, PhaseStopping, PhaseError},
+	PhaseStopping:     {PhaseStopped, PhaseError},
+}
+
+// CanTransitionTo reports whether a service in phase p may move to phase next.
+func (p Phase) CanTransitionTo(next Phase) bool {
+	return slices.Contains(phaseTransitions[p], next)
+}
+
+// IsTerminal reports whether p is a phase the service cannot leave.
+func (p Phase) IsTerminal() bool {
+	return p == PhaseStopped || p == PhaseError
+}
+
+// ErrInvalidTransition is returned by PhaseTracker.Transition for transitions
+// that are not allowed by Phase.CanTransitionTo.
+var ErrInvalidTransition = errors.New("invalid phase transition")
+
+// PhaseTransition records a single change of phase.
+type PhaseTransition struct {
+	// From is the phase before the transition.
+	From Phase
+	// To is the phase after the transition.
+	To Phase
+	// At is the time the transition happened.
+	At time.Time
+}
+
+// PhaseTracker holds the current phase of a service, validates transitions and
+// records their history. The zero value starts in PhaseWaiting.
+//
+// It is safe for concurrent use.
+type PhaseTracker struct {
+	mu        sync.Mutex
+	phase     Phase
+	history   []PhaseTransition
+	listeners []func(old, new Phase)
+
+	// pending holds the transitions not yet delivered to listeners, oldest first.
+	// While delivering is set, one goroutine is draining it.
+	pending    []PhaseTransition
+	delivering bool
+}
+
+// Phase returns the current phase.
+func (t *PhaseTracker) Phase() Phase {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.current()
+}
+
+// Transition moves the tracker to phase next, records the transition and
+// notifies the listeners registered with OnChange.
+//
+// If the current phase may not move to next, the phase is left unchanged and an
+// error wrapping ErrInvalidTransition is returned.
+func (t *PhaseTracker) Transition(next Phase) error {
+	t.mu.Lock()
+
+	prev := t.current()
+	if !prev.CanTransitionTo(next) {
+		t.mu.Unlock()
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, prev, next)
+	}
+
+	transition := PhaseTransition{From: prev, To: next, At: time.Now()}
+	t.phase = next
+	t.history = append(t.history, transition)
+	t.pending = append(t.pending, transition)
+
+	if t.delivering {
+		t.mu.Unlock()
+		return nil
+	}
+
+	t.delivering = true
+	t.mu.Unlock()
+
+	t.deliver()
+
+	return nil
+}
+
+// deliver notifies the listeners of pending transitions until none are left.
+//
+// If a listener panics, the tracker is released before the panic propagates, and
+// the transitions still pending are delivered by the next call to Transition.
+func (t *PhaseTracker) deliver() {
+	defer func() {
+		t.mu.Lock()
+		t.delivering = false
+		t.mu.Unlock()
+	}()
+
+	for {
+		t.mu.Lock()
+		if len(t.pending) == 0 {
+			t.mu.Unlock()
+			return
+		}
+
+		transition := t.pending[0]
+		t.pending = t.pending[1:]
+		listeners := slices.Clone(t.listeners)
+		t.mu.Unlock()
+
+		for _, fn := range listeners {
+			fn(transition.From, transition.To)
+		}
+	}
+}
+
+// OnChange registers fn to be called after every successful transition.
+//
+// Listeners are called in registration order, and notifications are delivered
+// one at a time in the order the transitions happened, so the last notification
+// a listener sees always matches the current phase.
+//
+// Listeners are usually called on the goroutine calling Transition before it
+// returns. If another goroutine is already delivering notifications, that
+// goroutine delivers the new transition instead and Transition returns without
+// waiting. In particular, a listener may call Transition itself: the nested
+// transition is delivered once the current notification has finished.
+func (t *PhaseTracker) OnChange(fn func(old, new Phase)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.listeners = append(t.listeners, fn)
+}
+
+// History returns the transitions recorded so far, oldest first.
+func (t *PhaseTracker) History() []PhaseTransition {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return slices.Clone(t.history)
+}
+
+// current returns the current phase, mapping the zero value to PhaseWaiting.
+// The caller must hold t.mu.
+func (t *PhaseTracker) current() Phase {
+	if t.phase == "" {
+		return PhaseWaiting
+	}
+
+	return t.phase
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPhaseTrackerNotifiesInTransitionOrder(t *testing.T) {
+	var tracker PhaseTracker
+
+	var (
+		mu   sync.Mutex
+		seen []PhaseTransition
+	)
+	tracker.OnChange(func(old, new Phase) {
+		mu.Lock()
+		defer mu.Unlock()
+		seen = append(seen, PhaseTransition{From: old, To: new})
+	})
+
+	if err := tracker.Transition(PhaseInitializing); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.Transition(PhaseRunning); err != nil {
+		t.Fatal(err)
+	}
+
+	// Concurrent toggles between RUNNING and DEGRADED; the ones that lose the race
+	// fail with ErrInvalidTransition and are not notified.
+	var wg sync.WaitGroup
+	for i := range 16 {
+		wg.Go(func() {
+			for range 100 {
+				if i%2 == 0 {
+					_ = tracker.Transition(PhaseDegraded)
+				} else {
+					_ = tracker.Transition(PhaseRunning)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	// A transition from this goroutine drains anything still pending.
+	if err := tracker.Transition(PhaseStopping); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	history := tracker.History()
+	if len(seen) != len(history) {
+		t.Fatalf("got %d notifications, want %d", len(seen), len(history))
+	}
+	for i, transition := range history {
+		if seen[i].From != transition.From || seen[i].To != transition.To {
+			t.Errorf("notification %d is %s -> %s, want %s -> %s",
+				i, seen[i].From, seen[i].To, transition.From, transition.To)
+		}
+	}
+	if last := seen[len(seen)-1].To; last != tracker.Phase() {
+		t.Errorf("last notification is for %s, current phase is %s", last, tracker.Phase())
+	}
+}
+
+func TestPhaseTrackerRecoversFromPanickingListener(t *testing.T) {
+	var tracker PhaseTracker
+
+	var seen []Phase
+	tracker.OnChange(func(_, new Phase) {
+		seen = append(seen, new)
+		if new == PhaseInitializing {
+			panic("listener failed")
+		}
+	})
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("listener panic was not propagated")
+			}
+		}()
+		_ = tracker.Transition(PhaseInitializing)
+	}()
+
+	if err := tracker.Transition(PhaseRunning); err != nil {
+		t.Fatal(err)
+	}
+
+	if tracker.Phase() != PhaseRunning {
+		t.Errorf("got phase %s, want %s", tracker.Phase(), PhaseRunning)
+	}
+	if len(seen) != 2 || seen[1] != PhaseRunning {
+		t.Errorf("got notifications %v, want [%s %s]", seen, PhaseInitializing, PhaseRunning)
+	}
+}
+
+func TestPhaseTrackerListenerMayTransition(t *testing.T) {
+	var tracker PhaseTracker
+
+	var seen []PhaseTransition
+	tracker.OnChange(func(old, new Phase) {
+		seen = append(seen, PhaseTransition{From: old, To: new})
+		if new == PhaseRunning {
+			if err := tracker.Transition(PhaseDegraded); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+	tracker.OnChange(func(old, new Phase) {
+		if new == PhaseRunning && tracker.Phase() != PhaseDegraded {
+			t.Errorf("nested transition not applied before later listeners ran")
+		}
+	})
+
+	for _, phase := range []Phase{PhaseInitializing, PhaseRunning} {
+		if err := tracker.Transition(phase); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []PhaseTransition{
+		{From: PhaseWaiting, To: PhaseInitializing},
+		{From: PhaseInitializing, To: PhaseRunning},
+		{From: PhaseRunning, To: PhaseDegraded},
+	}
+	if len(seen) != len(want) {
+		t.Fatalf("got notifications %v, want %v", seen, want)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Errorf("notification %d is %s -> %s, want %s -> %s",
+				i, seen[i].From, seen[i].To, want[i].From, want[i].To)
+		}
+	}
+}
+
+func TestPhaseTransitions(t *testing.T) {
+	phases := []Phase{
+		PhaseWaiting, PhaseInitializing, PhaseRunning, PhaseDegraded,
+		PhaseStopping, PhaseStopped, PhaseError,
+	}
+
+	allowed := map[Phase][]Phase{
+		PhaseWaiting:      {PhaseInitializing, PhaseStopping, PhaseError},
+		PhaseInitializing: {PhaseRunning, PhaseStopping, PhaseError},
+		PhaseRunning:      {PhaseDegraded, PhaseStopping, PhaseError},
+		PhaseDegraded:     {PhaseRunning, PhaseStopping, PhaseError},
+		PhaseStopping:     {PhaseStopped, PhaseError},
+	}
+
+	for _, from := range phases {
+		if got, want := from.IsTerminal(), len(allowed[from]) == 0; got != want {
+			t.Errorf("%s.IsTerminal() = %v, want %v", from, got, want)
+		}
+
+		for _, to := range phases {
+			want := false
+			for _, p := range allowed[from] {
+				want = want || p == to
+			}
+
+			if got := from.CanTransitionTo(to); got != want {
+				t.Errorf("%s.CanTransitionTo(%s) = %v, want %v", from, to, got, want)
+			}
+
+			tracker := &PhaseTracker{phase: from}
+			err := tracker.Transition(to)
+			switch {
+			case want && err != nil:
+				t.Errorf("transition %s -> %s failed: %v", from, to, err)
+			case !want && !errors.Is(err, ErrInvalidTransition):
+				t.Errorf("transition %s -> %s returned %v, want %v", from, to, err, ErrInvalidTransition)
+			case !want && tracker.Phase() != from:
+				t.Errorf("rejected transition %s -> %s changed the phase to %s", from, to, tracker.Phase())
+			}
+		}
+	}
+}
+
+func TestPhaseTrackerHistory(t *testing.T) {
+	var tracker PhaseTracker
+
+	if tracker.Phase() != PhaseWaiting {
+		t.Errorf("zero tracker is in phase %s, want %s", tracker.Phase(), PhaseWaiting)
+	}
+
+	before := time.Now()
+	for _, phase := range []Phase{PhaseInitializing, PhaseRunning, PhaseDegraded, PhaseRunning, PhaseStopping, PhaseStopped} {
+		if err := tracker.Transition(phase); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tracker.Transition(PhaseRunning); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("transition out of %s returned %v, want %v", PhaseStopped, err, ErrInvalidTransition)
+	}
+
+	want := []PhaseTransition{
+		{From: PhaseWaiting, To: PhaseInitializing},
+		{From: PhaseInitializing, To: PhaseRunning},
+		{From: PhaseRunning, To: PhaseDegraded},
+		{From: PhaseDegraded, To: PhaseRunning},
+		{From: PhaseRunning, To: PhaseStopping},
+		{From: PhaseStopping, To: PhaseStopped},
+	}
+
+	history := tracker.History()
+	if len(history) != len(want) {
+		t.Fatalf("got %d transitions, want %d", len(history), len(want))
+	}
+
+	last := before
+	for i, transition := range history {
+		if transition.From != want[i].From || transition.To != want[i].To {
+			t.Errorf("transition %d is %s -> %s, want %s -> %s",
+				i, transition.From, transition.To, want[i].From, want[i].To)
+		}
+		if transition.At.Before(last) {
+			t.Errorf("transition %d happened at %v, before %v", i, transition.At, last)
+		}
+		last = transition.At
+	}
+
+	// History returns a copy.
+	history[0].To = PhaseError
+	if tracker.History()[0].To != PhaseInitializing {
+		t.Error("modifying the returned history changed the tracker")
+	}
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+// prettyMessageWidth is the width messages are padded to, so that attributes of
+// consecutive records line up.
+const prettyMessageWidth = 40
+
+// PrettyHandler is a slog.Handler producing compact, human-friendly output for
+// local development: a short timestamp, a colored level, the message and aligned
+// key=value attributes.
+//
+// Colors are used only when writing to a terminal and the NO_COLOR environment
+// variable is empty or unset.
+type PrettyHandler struct {
+	mu    *sync.Mutex
+	w     io.Writer
+	opts  slog.HandlerOptions
+	color bool
+
+	// prefix is prepended to the keys of attributes, reflecting WithGroup calls.
+	prefix string
+	// attrs holds the formatted attributes added through WithAttrs.
+	attrs string
+}
+
+// NewPrettyHandler returns a PrettyHandler writing to w.
+//
+// Of opts, only Level and AddSource are honoured. If opts is nil, records at
+// slog.LevelInfo and above are written without source information.
+func NewPrettyHandler(w io.Writer, opts *slog.HandlerOptions) *PrettyHandler {
+	h := &PrettyHandler{
+		mu:    &sync.Mutex{},
+		w:     w,
+		color: colorEnabled(w),
+	}
+	if opts != nil {
+		h.opts = *opts
+	}
+
+	return h
+}
+
+// noColor reports whether the NO_COLOR environment variable disables colors.
+// Per the NO_COLOR convention, only a non-empty value does.
+func noColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// colorEnabled reports whether output to w should be colored.
+func colorEnabled(w io.Writer) bool {
+	if noColor() {
+		return false
+	}
+
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// Enabled reports whether records at the given level are written.
+func (h *PrettyHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+
+	return level >= minLevel
+}
+
+// Handle formats r as a single line and writes it.
+func (h *PrettyHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+
+	if !r.Time.IsZero() {
+		b.WriteString(h.paint(r.Time.Format(time.TimeOnly+".000"), color.Faint))
+		b.WriteByte(' ')
+	}
+
+	level, attr := prettyLevel(r.Level)
+	b.WriteString(h.paint(level, attr))
+	b.WriteByte(' ')
+
+	b.WriteString(r.Message)
+
+	var attrs strings.Builder
+	attrs.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		h.appendAttr(&attrs, h.prefix, a)
+		return true
+	})
+
+	if h.opts.AddSource && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		source := filepath.Base(frame.File) + ":" + strconv.Itoa(frame.Line)
+		attrs.WriteString(" " + h.paint(slog.SourceKey+"=", color.Faint) + source)
+	}
+
+	if attrs.Len() > 0 {
+		if pad := prettyMessageWidth - utf8.RuneCountInString(r.Message); pad > 0 {
+			b.WriteString(strings.Repeat(" ", pad))
+		}
+		b.WriteString(attrs.String())
+	}
+
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+// WithAttrs returns a PrettyHandler that includes attrs in every record.
+func (h *PrettyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	b.WriteString(h.attrs)
+	for _, a := range attrs {
+		h.appendAttr(&b, h.prefix, a)
+	}
+
+	h2 := *h
+	h2.attrs = b.String()
+
+	return &h2
+}
+
+// WithGroup returns a PrettyHandler that qualifies the keys of subsequent attributes with name.
+func (h *PrettyHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+
+	return &h2
+}
+
+// appendAttr writes a as " key=value" to b, flattening groups into dotted keys.
+func (h *PrettyHandler) appendAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	value := a.Value.Resolve()
+
+	if value.Kind() == slog.KindGroup {
+		group := value.Group()
+		if len(group) == 0 {
+			return
+		}
+
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, member := range group {
+			h.appendAttr(b, prefix, member)
+		}
+
+		return
+	}
+
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	b.WriteByte(' ')
+	b.WriteString(h.paint(prefix+a.Key+"=", color.Faint))
+
+	var text string
+	switch value.Kind() {
+	case slog.KindTime:
+		text = value.Time().Format(time.RFC3339Nano)
+	default:
+		text = value.String()
+	}
+
+	if text == "" || strings.ContainsAny(text, " \t\n\"=") {
+		text = strconv.Quote(text)
+	}
+
+	if err, ok := value.Any().(error); ok && err != nil {
+		text = h.paint(text, color.FgRed)
+	}
+
+	b.WriteString(text)
+}
+
+// paint returns s wrapped in the escape codes for attr if colors are enabled.
+func (h *PrettyHandler) paint(s string, attr color.Attribute) string {
+	if !h.color {
+		return s
+	}
+
+	c := color.New(attr)
+	c.EnableColor()
+
+	return c.Sprint(s)
+}
+
+// prettyLevel returns the three-letter label and color for level.
+func prettyLevel(level slog.Level) (string, color.Attribute) {
+	switch {
+	case level >= slog.LevelError:
+		return withOffset("ERR", level-slog.LevelError), color.FgRed
+	case level >= slog.LevelWarn:
+		return withOffset("WRN", level-slog.LevelWarn), color.FgYellow
+	case level >= slog.LevelInfo:
+		return withOffset("INF", level-slog.LevelInfo), color.FgGreen
+	default:
+		return withOffset("DBG", level-slog.LevelDebug), color.FgMagenta
+	}
+}
+
+// withOffset appends a non-zero level offset to label, as slog does for custom levels.
+func withOffset(label string, offset slog.Level) string {
+	if offset == 0 {
+		return label
+	}
+
+	return fmt.Sprintf("%s%+d", label, int(offset))
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPrettyHandlerAlignsAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewPrettyHandler(&buf, nil))
+
+	logger.Info("ascii message", "k", 1)
+	logger.Info("größenänderung über", "k", 1)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), buf.String())
+	}
+
+	var columns []int
+	for _, line := range lines {
+		i := strings.Index(line, " k=")
+		if i < 0 {
+			t.Fatalf("no attribute in %q", line)
+		}
+		columns = append(columns, utf8.RuneCountInString(line[:i]))
+	}
+	if columns[0] != columns[1] {
+		t.Errorf("attributes start at columns %v, want equal:\n%s", columns, buf.String())
+	}
+}
+
+func TestNoColor(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{value: "", want: false},
+		{value: "1", want: true},
+		{value: "false", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("NO_COLOR", tt.value)
+
+		if got := noColor(); got != tt.want {
+			t.Errorf("NO_COLOR=%q: got %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
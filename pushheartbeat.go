@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// pushHeartbeatTimeout bounds each request made to a heartbeat monitor.
+const pushHeartbeatTimeout = 10 * time.Second
+
+// PushHeartbeat requests url every interval until ctx is cancelled.
+//
+// It suits push-style monitors such as Healthchecks.io or Cronitor, which alert when
+// check-ins stop arriving, for services no pull probe can reach. Failed pings are
+// logged to the logger in ctx and retried on the next interval. Call it while the
+// service is running, and PushHeartbeatFailure if it crashes.
+func PushHeartbeat(ctx context.Context, url string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := pushHeartbeat(ctx, url, ""); err != nil {
+			Logger(ctx).Warn("failed to push heartbeat", "url", url, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PushHeartbeatFailure reports a failure to the monitor at url, such as the "/fail"
+// endpoint of a Healthchecks.io check or a Cronitor URL with state=fail.
+//
+// The message of cause, if any, is sent as the request body, where monitors that
+// support it show it alongside the alert. PushHeartbeatFailure is meant to be called
+// while crashing, so it ignores the cancellation of ctx.
+func PushHeartbeatFailure(ctx context.Context, url string, cause error) error {
+	var body string
+	if cause != nil {
+		body = cause.Error()
+	}
+
+	return pushHeartbeat(context.WithoutCancel(ctx), url, body)
+}
+
+// pushHeartbeat sends a single ping to url, as a POST with body if body is not empty.
+func pushHeartbeat(ctx context.Context, url, body string) error {
+	ctx, cancel := context.WithTimeout(ctx, pushHeartbeatTimeout)
+	defer cancel()
+
+	method := http.MethodGet
+	if body != "" {
+		method = http.MethodPost
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
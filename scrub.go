@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// Redacted is the replacement value used by the built-in scrubbers.
+const Redacted = "[REDACTED]"
+
+// Scrubber rewrites a value before it leaves the process, returning the value unchanged
+// if it is not sensitive. key is the name the value is recorded under, such as a log
+// attribute key or span attribute key.
+type Scrubber func(key, value string) string
+
+// RedactFields returns a Scrubber that replaces the value of every field whose key
+// matches one of fields, ignoring case, with Redacted.
+func RedactFields(fields ...string) Scrubber {
+	set := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		set[strings.ToLower(field)] = struct{}{}
+	}
+
+	return func(key, value string) string {
+		if _, ok := set[strings.ToLower(key)]; ok {
+			return Redacted
+		}
+
+		return value
+	}
+}
+
+// RedactPattern returns a Scrubber that replaces every match of re in a value with Redacted.
+func RedactPattern(re *regexp.Regexp) Scrubber {
+	return func(_, value string) string {
+		return re.ReplaceAllString(value, Redacted)
+	}
+}
+
+// Scrubbers is an ordered list of scrubbers applied as one.
+//
+// It is the central place to declare compliance rules; the same list can be passed to
+// NewScrubHandler for logs and applied with Scrub to span attributes and error reports.
+type Scrubbers []Scrubber
+
+// Scrub applies every scrubber in order to value and returns the result.
+func (s Scrubbers) Scrub(key, value string) string {
+	for _, scrub := range s {
+		value = scrub(key, value)
+	}
+
+	return value
+}
+
+// ScrubHandler is a slog.Handler that applies scrubbers to the message and attributes
+// of every record before delegating to the wrapped handler.
+type ScrubHandler struct {
+	next      slog.Handler
+	scrubbers Scrubbers
+}
+
+// NewScrubHandler returns a ScrubHandler that scrubs records with scrubbers before passing them to next.
+func NewScrubHandler(next slog.Handler, scrubbers ...Scrubber) *ScrubHandler {
+	return &ScrubHandler{next: next, scrubbers: scrubbers}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (h *ScrubHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle scrubs r and passes the result to the wrapped handler.
+func (h *ScrubHandler) Handle(ctx context.Context, r slog.Record) error {
+	scrubbed := slog.NewRecord(r.Time, r.Level, h.scrubbers.Scrub(slog.MessageKey, r.Message), r.PC)
+	r.Attrs(func(attr slog.Attr) bool {
+		scrubbed.AddAttrs(h.scrubAttr(attr))
+		return true
+	})
+
+	return h.next.Handle(ctx, scrubbed)
+}
+
+// WithAttrs returns a ScrubHandler wrapping next.WithAttrs with the scrubbed attrs.
+func (h *ScrubHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	scrubbed := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		scrubbed[i] = h.scrubAttr(attr)
+	}
+
+	return &ScrubHandler{next: h.next.WithAttrs(scrubbed), scrubbers: h.scrubbers}
+}
+
+// WithGroup returns a ScrubHandler wrapping next.WithGroup(name).
+func (h *ScrubHandler) WithGroup(name string) slog.Handler {
+	return &ScrubHandler{next: h.next.WithGroup(name), scrubbers: h.scrubbers}
+}
+
+// scrubAttr returns attr with its value scrubbed, descending into groups.
+//
+// Non-string values are scrubbed through their string form and replaced
+// by a string only if a scrubber changed them.
+func (h *ScrubHandler) scrubAttr(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+
+	switch value.Kind() {
+	case slog.KindGroup:
+		group := value.Group()
+		scrubbed := make([]slog.Attr, len(group))
+		for i, member := range group {
+			scrubbed[i] = h.scrubAttr(member)
+		}
+
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(scrubbed...)}
+	case slog.KindString:
+		return slog.String(attr.Key, h.scrubbers.Scrub(attr.Key, value.String()))
+	default:
+		original := value.String()
+		if scrubbed := h.scrubbers.Scrub(attr.Key, original); scrubbed != original {
+			return slog.String(attr.Key, scrubbed)
+		}
+
+		return slog.Attr{Key: attr.Key, Value: value}
+	}
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// TenantBaggageKey is the OpenTelemetry baggage key under which WithTenant
+// propagates the tenant ID.
+const TenantBaggageKey = "tenant.id"
+
+// tenantKey is an unexported type used as the key for storing the tenant ID within context.Context.
+type tenantKey struct{}
+
+// WithTenant returns a new context derived from ctx that carries the tenant ID id.
+//
+// The ID is added to the OpenTelemetry baggage of ctx so it propagates to downstream
+// services. Use TenantHandler to include it in log records.
+func WithTenant(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, tenantKey{}, id)
+
+	member, err := baggage.NewMemberRaw(TenantBaggageKey, id)
+	if err != nil {
+		Logger(ctx).Warn("tenant id not propagated via baggage", "error", err)
+		return ctx
+	}
+
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		Logger(ctx).Warn("tenant id not propagated via baggage", "error", err)
+		return ctx
+	}
+
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// Tenant returns the tenant ID stored in ctx by WithTenant.
+//
+// If ctx carries no tenant ID, it falls back to the tenant in the OpenTelemetry
+// baggage of ctx, so IDs propagated by upstream services are recognised.
+func Tenant(ctx context.Context) (string, bool) {
+	if id, ok := ctx.Value(tenantKey{}).(string); ok {
+		return id, true
+	}
+
+	member := baggage.FromContext(ctx).Member(TenantBaggageKey)
+	if member.Key() == "" {
+		return "", false
+	}
+
+	return member.Value(), true
+}
+
+// TenantHandler is a slog.Handler that adds the tenant of a record's context, as
+// returned by Tenant, as a tenant attribute.
+//
+// The tenant is always a top-level attribute, even when the logger has open groups.
+// Records logged without a context, or whose context has no tenant, pass through unchanged.
+type TenantHandler struct {
+	next groupedHandler
+}
+
+// NewTenantHandler returns a TenantHandler that delegates to next.
+func NewTenantHandler(next slog.Handler) *TenantHandler {
+	return &TenantHandler{next: newGroupedHandler(next)}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (h *TenantHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.handler.Enabled(ctx, level)
+}
+
+// Handle adds the tenant found in ctx to r and passes it to the wrapped handler.
+func (h *TenantHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if id, ok := Tenant(ctx); ok {
+			return h.next.handleWith(ctx, r, slog.String("tenant", id))
+		}
+	}
+
+	return h.next.handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a TenantHandler wrapping next.WithAttrs(attrs).
+func (h *TenantHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &TenantHandler{next: h.next.withAttrs(attrs)}
+}
+
+// WithGroup returns a TenantHandler wrapping next.WithGroup(name).
+func (h *TenantHandler) WithGroup(name string) slog.Handler {
+	return &TenantHandler{next: h.next.withGroup(name)}
+}
+
+// TenantOverflow is the label TenantCardinalityGuard uses for tenants beyond its limit.
+const TenantOverflow = "other"
+
+// TenantCardinalityGuard bounds the number of distinct tenant values used as metric
+// attributes. The first Max tenants seen are reported as-is; all later ones are
+// folded into TenantOverflow, protecting the metrics backend from unbounded cardinality.
+//
+// It is safe for concurrent use.
+type TenantCardinalityGuard struct {
+	// Max is the number of distinct tenants reported individually.
+	Max int
+
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+// Label returns the value to use as the tenant metric attribute for ctx.
+//
+// It returns an empty string if ctx carries no tenant.
+func (g *TenantCardinalityGuard) Label(ctx context.Context) string {
+	id, ok := Tenant(ctx)
+	if !ok {
+		return ""
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, ok := g.seen[id]; ok {
+		return id
+	}
+	if len(g.seen) >= g.Max {
+		return TenantOverflow
+	}
+
+	if g.seen == nil {
+		g.seen = make(map[string]struct{})
+	}
+	g.seen[id] = struct{}{}
+
+	return id
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func TestTenantHandler(t *testing.T) {
+	member, err := baggage.NewMemberRaw(TenantBaggageKey, "upstream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bag, err := baggage.New(member)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{name: "none", ctx: context.Background()},
+		{name: "context", ctx: WithTenant(context.Background(), "acme"), want: "acme"},
+		{name: "replaced", ctx: WithTenant(WithTenant(context.Background(), "acme"), "globex"), want: "globex"},
+		{name: "baggage", ctx: baggage.ContextWithBaggage(context.Background(), bag), want: "upstream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(NewTenantHandler(slog.NewJSONHandler(&buf, nil)))
+
+			logger.WithGroup("req").InfoContext(tt.ctx, "hello", "a", 1)
+
+			if n := strings.Count(buf.String(), `"tenant"`); tt.want != "" && n != 1 {
+				t.Errorf("tenant appears %d times in %s", n, buf.String())
+			}
+
+			var record map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatal(err)
+			}
+
+			got, _ := record["tenant"].(string)
+			if got != tt.want {
+				t.Errorf("got top-level tenant %q, want %q in %s", got, tt.want, buf.String())
+			}
+			if group, _ := record["req"].(map[string]any); group["a"] != float64(1) {
+				t.Errorf("got group req %v, want a=1", record["req"])
+			}
+		})
+	}
+}
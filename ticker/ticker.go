@@ -0,0 +1,214 @@
+// Package ticker runs a function periodically with optional jitter and alignment,
+// skipping ticks while a previous run is still in progress.
+package ticker
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go.aledante.io/service"
+)
+
+// Func is the function run on every tick.
+type Func func(ctx context.Context) error
+
+// ShutdownMode controls how a Ticker behaves when its context is cancelled.
+type ShutdownMode int
+
+const (
+	// ShutdownAbort cancels the context of an in-flight run and waits for it to return.
+	ShutdownAbort ShutdownMode = iota
+	// ShutdownWait lets an in-flight run complete without cancelling its context.
+	ShutdownWait
+	// ShutdownFinalRun lets an in-flight run complete and then runs the function one final time.
+	ShutdownFinalRun
+)
+
+// Stats is a point-in-time snapshot of a Ticker's activity.
+type Stats struct {
+	// Runs is the number of completed runs, including failed ones.
+	Runs uint64
+	// Failures is the number of runs that returned an error.
+	Failures uint64
+	// Skipped is the number of ticks dropped because the previous run had not finished.
+	Skipped uint64
+	// LastDuration is the duration of the most recent completed run.
+	LastDuration time.Duration
+	// LastError is the error returned by the most recent completed run, if any.
+	LastError error
+}
+
+// Option configures a Ticker.
+type Option func(*Ticker)
+
+// WithName sets the name attached to the ticker's log records.
+func WithName(name string) Option {
+	return func(t *Ticker) {
+		t.name = name
+	}
+}
+
+// WithJitter delays each tick by a random duration in [0, jitter).
+func WithJitter(jitter time.Duration) Option {
+	return func(t *Ticker) {
+		t.jitter = jitter
+	}
+}
+
+// WithAlignment aligns ticks to multiples of the interval on the wall clock,
+// so an interval of one minute ticks at the top of every minute.
+func WithAlignment() Option {
+	return func(t *Ticker) {
+		t.align = true
+	}
+}
+
+// WithShutdown sets the shutdown behavior of the ticker.
+//
+// For ShutdownWait and ShutdownFinalRun, timeout bounds the time spent finishing
+// runs after cancellation; once it elapses, the run context is cancelled.
+// A timeout of zero waits indefinitely.
+func WithShutdown(mode ShutdownMode, timeout time.Duration) Option {
+	return func(t *Ticker) {
+		t.shutdownMode = mode
+		t.shutdownTimeout = timeout
+	}
+}
+
+// Ticker runs a Func every interval until its context is cancelled.
+type Ticker struct {
+	interval        time.Duration
+	fn              Func
+	name            string
+	jitter          time.Duration
+	align           bool
+	shutdownMode    ShutdownMode
+	shutdownTimeout time.Duration
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// New returns a Ticker running fn every interval.
+//
+// The ticker does not start until Run is called. New panics if interval is not positive.
+func New(interval time.Duration, fn Func, opts ...Option) *Ticker {
+	if interval <= 0 {
+		panic("ticker: non-positive interval")
+	}
+
+	t := &Ticker{
+		interval: interval,
+		fn:       fn,
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// Stats returns a snapshot of the ticker's activity.
+func (t *Ticker) Stats() Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.stats
+}
+
+// Run ticks until ctx is cancelled, then finishes according to the configured ShutdownMode.
+//
+// Runs are started on their own goroutine; a tick that fires while the previous
+// run is still in progress is skipped. Run returns once no run is in progress.
+func (t *Ticker) Run(ctx context.Context) {
+	runCtx, cancelRun := ctx, context.CancelFunc(func() {})
+	if t.shutdownMode != ShutdownAbort {
+		runCtx, cancelRun = context.WithCancel(context.WithoutCancel(ctx))
+	}
+	defer cancelRun()
+
+	var (
+		wg      sync.WaitGroup
+		running = make(chan struct{}, 1)
+	)
+
+	timer := time.NewTimer(t.next(time.Now()))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			t.shutdown(runCtx, cancelRun, &wg)
+			return
+		case <-timer.C:
+			select {
+			case running <- struct{}{}:
+				wg.Go(func() {
+					defer func() { <-running }()
+					t.invoke(runCtx)
+				})
+			default:
+				t.mu.Lock()
+				t.stats.Skipped++
+				t.mu.Unlock()
+			}
+
+			timer.Reset(t.next(time.Now()))
+		}
+	}
+}
+
+// shutdown waits for the in-flight run and performs the final run, if configured.
+func (t *Ticker) shutdown(runCtx context.Context, cancelRun context.CancelFunc, wg *sync.WaitGroup) {
+	if t.shutdownMode != ShutdownAbort && t.shutdownTimeout > 0 {
+		deadline := time.AfterFunc(t.shutdownTimeout, cancelRun)
+		defer deadline.Stop()
+	}
+
+	wg.Wait()
+
+	if t.shutdownMode == ShutdownFinalRun && runCtx.Err() == nil {
+		t.invoke(runCtx)
+	}
+}
+
+// invoke runs the function once and records the outcome.
+func (t *Ticker) invoke(ctx context.Context) {
+	start := time.Now()
+	err := t.fn(ctx)
+	duration := time.Since(start)
+
+	t.mu.Lock()
+	t.stats.Runs++
+	t.stats.LastDuration = duration
+	t.stats.LastError = err
+	if err != nil {
+		t.stats.Failures++
+	}
+	t.mu.Unlock()
+
+	if err != nil {
+		service.Logger(ctx).Error("ticker run failed",
+			"ticker", t.name,
+			"duration", duration,
+			"error", err,
+		)
+	}
+}
+
+// next returns the delay until the tick following now.
+func (t *Ticker) next(now time.Time) time.Duration {
+	delay := t.interval
+	if t.align {
+		delay = now.Truncate(t.interval).Add(t.interval).Sub(now)
+	}
+
+	if t.jitter > 0 {
+		delay += rand.N(t.jitter)
+	}
+
+	return delay
+}
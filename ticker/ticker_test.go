@@ -0,0 +1,177 @@
+package ticker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.aledante.io/service"
+)
+
+// quietContext returns a context whose logger discards records.
+func quietContext() context.Context {
+	return service.WithLogger(context.Background(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// runAsync starts t.Run(ctx) and returns a channel closed once it returns.
+func runAsync(ctx context.Context, t *Ticker) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		t.Run(ctx)
+	}()
+
+	return done
+}
+
+// waitDone fails the test if done is not closed within timeout.
+func waitDone(tb testing.TB, done <-chan struct{}, timeout time.Duration) {
+	tb.Helper()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		tb.Fatal("Run did not return")
+	}
+}
+
+func TestTickerSkipsWhileRunning(t *testing.T) {
+	var active, overlaps atomic.Int32
+
+	tk := New(2*time.Millisecond, func(context.Context) error {
+		if active.Add(1) > 1 {
+			overlaps.Add(1)
+		}
+		defer active.Add(-1)
+
+		time.Sleep(15 * time.Millisecond)
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Millisecond)
+	defer cancel()
+	tk.Run(ctx)
+
+	stats := tk.Stats()
+	if stats.Runs == 0 {
+		t.Error("no runs completed")
+	}
+	if stats.Skipped == 0 {
+		t.Error("no ticks skipped although runs outlast the interval")
+	}
+	if overlaps.Load() != 0 {
+		t.Errorf("%d runs overlapped", overlaps.Load())
+	}
+}
+
+func TestTickerRecordsFailures(t *testing.T) {
+	errRun := errors.New("run failed")
+
+	tk := New(time.Millisecond, func(context.Context) error { return errRun })
+
+	ctx, cancel := context.WithTimeout(quietContext(), 20*time.Millisecond)
+	defer cancel()
+	tk.Run(ctx)
+
+	stats := tk.Stats()
+	if stats.Runs == 0 || stats.Failures != stats.Runs {
+		t.Errorf("got %d runs and %d failures, want every run to fail", stats.Runs, stats.Failures)
+	}
+	if !errors.Is(stats.LastError, errRun) {
+		t.Errorf("got last error %v, want %v", stats.LastError, errRun)
+	}
+}
+
+func TestTickerShutdown(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    ShutdownMode
+		timeout time.Duration
+		// release makes the in-flight run return on its own after this delay;
+		// zero means it only returns once its context is cancelled.
+		release time.Duration
+
+		wantCancelled bool
+		wantFinalRun  bool
+		minDuration   time.Duration
+	}{
+		{name: "abort", mode: ShutdownAbort, wantCancelled: true},
+		{name: "wait", mode: ShutdownWait, release: 30 * time.Millisecond, minDuration: 30 * time.Millisecond},
+		{name: "wait with timeout", mode: ShutdownWait, timeout: 20 * time.Millisecond, wantCancelled: true, minDuration: 20 * time.Millisecond},
+		{name: "final run", mode: ShutdownFinalRun, release: 10 * time.Millisecond, wantFinalRun: true, minDuration: 10 * time.Millisecond},
+		{name: "final run with timeout", mode: ShutdownFinalRun, timeout: 20 * time.Millisecond, wantCancelled: true, minDuration: 20 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				runs      atomic.Int32
+				cancelled atomic.Bool
+				started   = make(chan struct{})
+			)
+
+			tk := New(time.Millisecond, func(ctx context.Context) error {
+				if runs.Add(1) > 1 {
+					// The final run must get a live context and returns immediately.
+					if ctx.Err() != nil {
+						t.Error("final run started with a cancelled context")
+					}
+					return nil
+				}
+				close(started)
+
+				var release <-chan time.Time
+				if tt.release > 0 {
+					release = time.After(tt.release)
+				}
+
+				select {
+				case <-release:
+				case <-ctx.Done():
+					cancelled.Store(true)
+				}
+				return nil
+			}, WithShutdown(tt.mode, tt.timeout))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := runAsync(ctx, tk)
+
+			<-started
+			begin := time.Now()
+			cancel()
+			waitDone(t, done, time.Second)
+
+			if elapsed := time.Since(begin); elapsed < tt.minDuration {
+				t.Errorf("Run returned after %v, want at least %v", elapsed, tt.minDuration)
+			}
+			if cancelled.Load() != tt.wantCancelled {
+				t.Errorf("in-flight run cancelled = %v, want %v", cancelled.Load(), tt.wantCancelled)
+			}
+			if finalRun := runs.Load() == 2; finalRun != tt.wantFinalRun {
+				t.Errorf("final run = %v, want %v (%d runs)", finalRun, tt.wantFinalRun, runs.Load())
+			}
+		})
+	}
+}
+
+func TestTickerNext(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 30, 0, time.UTC)
+
+	if got := New(time.Minute, nil).next(now); got != time.Minute {
+		t.Errorf("unaligned delay is %v, want %v", got, time.Minute)
+	}
+	if got := New(time.Minute, nil, WithAlignment()).next(now); got != 30*time.Second {
+		t.Errorf("aligned delay is %v, want %v", got, 30*time.Second)
+	}
+
+	jittered := New(time.Minute, nil, WithAlignment(), WithJitter(time.Second))
+	for range 100 {
+		if got := jittered.next(now); got < 30*time.Second || got >= 31*time.Second {
+			t.Fatalf("aligned delay with jitter is %v, want within [30s, 31s)", got)
+		}
+	}
+}
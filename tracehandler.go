@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceHandler is a slog.Handler that adds the IDs of the span active in a record's
+// context as trace_id and span_id attributes, so log records can be correlated with traces.
+//
+// The IDs are always top-level attributes, even when the logger has open groups.
+// Records logged without a context, or whose context has no valid span, pass through unchanged.
+type TraceHandler struct {
+	next groupedHandler
+}
+
+// NewTraceHandler returns a TraceHandler that delegates to next.
+func NewTraceHandler(next slog.Handler) *TraceHandler {
+	return &TraceHandler{next: newGroupedHandler(next)}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (h *TraceHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.handler.Enabled(ctx, level)
+}
+
+// Handle adds the trace and span IDs found in ctx to r and passes it to the wrapped handler.
+func (h *TraceHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			return h.next.handleWith(ctx, r,
+				slog.String("trace_id", sc.TraceID().String()),
+				slog.String("span_id", sc.SpanID().String()),
+			)
+		}
+	}
+
+	return h.next.handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a TraceHandler wrapping next.WithAttrs(attrs).
+func (h *TraceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &TraceHandler{next: h.next.withAttrs(attrs)}
+}
+
+// WithGroup returns a TraceHandler wrapping next.WithGroup(name).
+func (h *TraceHandler) WithGroup(name string) slog.Handler {
+	return &TraceHandler{next: h.next.withGroup(name)}
+}
+
+// groupedHandler records the WithAttrs and WithGroup calls made on a base handler,
+// so that wrapping handlers can add attributes at the top level of a record after
+// groups have been opened.
+type groupedHandler struct {
+	base slog.Handler
+	// handler is base with every recorded call applied.
+	handler slog.Handler
+	// calls holds the recorded calls in order; grouped reports whether any is a WithGroup.
+	calls   []handlerCall
+	grouped bool
+}
+
+// handlerCall is a single recorded WithAttrs or WithGroup call.
+type handlerCall struct {
+	group string
+	attrs []slog.Attr
+}
+
+// newGroupedHandler returns a groupedHandler for base with no calls recorded.
+func newGroupedHandler(base slog.Handler) groupedHandler {
+	return groupedHandler{base: base, handler: base}
+}
+
+// withAttrs returns g with a WithAttrs(attrs) call recorded.
+func (g groupedHandler) withAttrs(attrs []slog.Attr) groupedHandler {
+	if len(attrs) == 0 {
+		return g
+	}
+
+	g.calls = append(g.calls[:len(g.calls):len(g.calls)], handlerCall{attrs: attrs})
+	g.handler = g.handler.WithAttrs(attrs)
+
+	return g
+}
+
+// withGroup returns g with a WithGroup(name) call recorded.
+func (g groupedHandler) withGroup(name string) groupedHandler {
+	if name == "" {
+		return g
+	}
+
+	g.calls = append(g.calls[:len(g.calls):len(g.calls)], handlerCall{group: name})
+	g.handler = g.handler.WithGroup(name)
+	g.grouped = true
+
+	return g
+}
+
+// handleWith passes r to the handler with attrs added at the top level.
+func (g groupedHandler) handleWith(ctx context.Context, r slog.Record, attrs ...slog.Attr) error {
+	if !g.grouped {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+		return g.handler.Handle(ctx, r)
+	}
+
+	// Attributes added to a record land in the innermost group, so add them to the
+	// base handler instead and replay the recorded calls on top.
+	h := g.base.WithAttrs(attrs)
+	for _, call := range g.calls {
+		if call.group != "" {
+			h = h.WithGroup(call.group)
+		} else {
+			h = h.WithAttrs(call.attrs)
+		}
+	}
+
+	return h.Handle(ctx, r)
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceHandlerAddsTopLevelIDs(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x01},
+		SpanID:  trace.SpanID{0x02},
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	var buf bytes.Buffer
+	logger := slog.New(NewTraceHandler(slog.NewJSONHandler(&buf, nil)))
+
+	logger.With("service", "api").WithGroup("req").With("a", 1).InfoContext(ctx, "hello", "b", 2)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+
+	if record["trace_id"] != sc.TraceID().String() {
+		t.Errorf("got trace_id %v, want %s", record["trace_id"], sc.TraceID())
+	}
+	if record["span_id"] != sc.SpanID().String() {
+		t.Errorf("got span_id %v, want %s", record["span_id"], sc.SpanID())
+	}
+	if record["service"] != "api" {
+		t.Errorf("got service %v, want api", record["service"])
+	}
+
+	group, _ := record["req"].(map[string]any)
+	if group["a"] != float64(1) || group["b"] != float64(2) {
+		t.Errorf("got group req %v, want a=1 and b=2", record["req"])
+	}
+	if _, ok := group["trace_id"]; ok {
+		t.Errorf("trace_id was added to group req: %v", group)
+	}
+}
+
+func TestTraceHandlerWithoutSpan(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewTraceHandler(slog.NewJSONHandler(&buf, nil)))
+
+	logger.WithGroup("req").InfoContext(context.Background(), "hello", "a", 1)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := record["trace_id"]; ok {
+		t.Errorf("trace_id added without a span: %v", record)
+	}
+	if group, _ := record["req"].(map[string]any); group["a"] != float64(1) {
+		t.Errorf("got group req %v, want a=1", record["req"])
+	}
+}